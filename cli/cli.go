@@ -6,6 +6,7 @@ import (
 
 	"github.com/itchyny/gojq"
 	"github.com/jessevdk/go-flags"
+	"github.com/pkg/errors"
 )
 
 type CLI struct {
@@ -41,6 +42,19 @@ func (c *CLI) run(args []string) error {
 		return nil
 	}
 
+	if options.Preset != "" {
+		// the arguments are all input files as the preset is the query
+		p, ok := presets[string(options.Preset)]
+		if !ok {
+			return errors.Errorf("unknown preset: %s", options.Preset)
+		}
+		inputFiles := queryAndInputFiles
+		if len(inputFiles) == 0 {
+			inputFiles = []string{p.input}
+		}
+		queryAndInputFiles = append([]string{p.query}, inputFiles...)
+	}
+
 	queryString := ""
 	if len(queryAndInputFiles) > 0 {
 		queryString = queryAndInputFiles[0]
@@ -55,9 +69,14 @@ func (c *CLI) run(args []string) error {
 		return err
 	}
 
-	iter, err := c.createInputIter(queryString, inputFiles)
-	if err != nil {
-		return err
+	var iter inputIter
+	if options.List {
+		iter = newValueIter("--list", listFormats())
+	} else {
+		iter, err = c.createInputIter(queryString, inputFiles)
+		if err != nil {
+			return err
+		}
 	}
 	defer iter.Close()
 
@@ -1,15 +1,26 @@
 package cli
 
 var options struct {
-	Version             bool `short:"v" long:"version" description:"print version"`
-	OutputCompact       bool `short:"c" long:"compact-output" description:"compact output"`
-	OutputRaw           bool `short:"r" long:"raw-output" description:"output raw strings"`
-	OutputJoin          bool `short:"j" long:"join-output" description:"stop printing a new line after each output"`
-	OutputQueryFriendly bool `short:"f" long:"query-friendly" description:"use query-friendly key names (i.e. replace white spaces and special characters with '_')"`
-	OutputNul           bool `short:"0" long:"nul-output" description:"print NUL after each output"`
-	OutputColor         bool `short:"C" long:"color-output" description:"colorize output even if piped"`
-	OutputMono          bool `short:"M" long:"monochrome-output" description:"stop colorizing output"`
-	OutputYAML          bool `long:"yaml-output" description:"output by YAML"`
-	OutputIndent        *int `long:"indent" description:"number of spaces for indentation"`
-	OutputTab           bool `long:"tab" description:"use tabs for indentation"`
+	Version             bool       `short:"v" long:"version" description:"print version"`
+	OutputCompact       bool       `short:"c" long:"compact-output" description:"compact output"`
+	OutputRaw           bool       `short:"r" long:"raw-output" description:"output raw strings"`
+	OutputJoin          bool       `short:"j" long:"join-output" description:"stop printing a new line after each output"`
+	OutputQueryFriendly bool       `short:"f" long:"query-friendly" description:"use query-friendly key names (i.e. replace white spaces and special characters with '_' in top-level keys)"`
+	OutputNul           bool       `short:"0" long:"nul-output" description:"print NUL after each output"`
+	OutputColor         bool       `short:"C" long:"color-output" description:"colorize output even if piped"`
+	OutputMono          bool       `short:"M" long:"monochrome-output" description:"stop colorizing output"`
+	OutputYAML          bool       `long:"yaml-output" description:"output by YAML"`
+	OutputIndent        *int       `long:"indent" description:"number of spaces for indentation"`
+	OutputTab           bool       `long:"tab" description:"use tabs for indentation"`
+	Preset              presetName `long:"preset" description:"run a built-in query (privileged) instead of a given one; all arguments are input files"`
+	Flat                bool       `long:"flat" description:"flatten nested entries (e.g. /proc/iomem) into an array with depth field"`
+	ModuleParameters    bool       `long:"module-parameters" description:"join parameters in /sys/module into each row of /proc/modules"`
+	Depth               int        `long:"depth" default:"1" description:"maximum depth to walk directories which have no dedicated parser (subdirectories beyond it are null)"`
+	FollowSymlinks      bool       `long:"follow-symlinks" description:"follow symbolic links to directories instead of recording their targets"`
+	As                  string     `long:"as" choice:"kv" choice:"table" choice:"lines" choice:"chunks" choice:"raw" description:"parse the input generically instead of by a dedicated parser"`
+	Separator           string     `long:"separator" choice:"colon" choice:"space" choice:"equals" description:"separator of keys and values (or columns) for --as (default: colon, or space for --as table)"`
+	SkipRows            int        `long:"skip-rows" description:"number of rows to skip at the beginning of the input for --as"`
+	Header              bool       `long:"header" description:"use the first row (after --skip-rows) as column names for --as table"`
+	List                bool       `long:"list" description:"list the supported files instead of reading one"`
+	Format              formatName `long:"format" description:"parse stdin as the given file (e.g. /proc/meminfo, or meminfo for short)"`
 }
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+)
+
+type preset struct {
+	query       string
+	input       string
+	description string
+}
+
+// presets are built-in queries which can be selected by --preset. The input
+// is used when no file is given.
+var presets = map[string]preset{
+	"privileged": {
+		query:       `.[] | select((.capabilities.effective | any(. == "CAP_SYS_ADMIN")) or (.uid.effective == 0 and .seccomp == "disabled"))`,
+		input:       "/proc/*/attr",
+		description: "processes with effective CAP_SYS_ADMIN, or running as uid 0 without seccomp",
+	},
+}
+
+// presetName is the type of --preset to complete it with the names and
+// descriptions of the presets.
+type presetName string
+
+func (n presetName) Complete(match string) []flags.Completion {
+	var result []flags.Completion
+	for name, p := range presets {
+		if strings.HasPrefix(name, match) {
+			result = append(result, flags.Completion{Item: name, Description: p.description})
+		}
+	}
+	return result
+}
@@ -0,0 +1,192 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// parseAs parses r generically by the combinators selected by opts.As, for
+// files which have no dedicated format.
+func parseAs(r io.Reader, opts *Options) (interface{}, error) {
+	r, err := skipRows(r, opts.SkipRows)
+	if err != nil {
+		return nil, err
+	}
+
+	switch opts.As {
+	case "kv":
+		splitter, err := asLineSplitter(opts.Separator)
+		if err != nil {
+			return nil, err
+		}
+		return createAsMapParser(createLineParser(splitter, parseAsValue))(r)
+
+	case "chunks":
+		splitter, err := asLineSplitter(opts.Separator)
+		if err != nil {
+			return nil, err
+		}
+		return createChunkParser(createLineParser(splitter, parseAsValue))(r)
+
+	case "table":
+		splitter, err := asColumnSplitter(opts.Separator)
+		if err != nil {
+			return nil, err
+		}
+		headerParser := noTableHeader
+		if opts.Header {
+			headerParser = skipTableHeader(1)
+		}
+		return createTableParser(headerParser, createTableRowParser(splitter, createAsColumnsParser(splitter)))(r)
+
+	case "lines":
+		return parseAsLines(r)
+
+	case "raw":
+		return parseAsRaw(r)
+	}
+
+	return nil, errors.Errorf("unknown generic format: %s", opts.As)
+}
+
+// createAsMapParser is createMapParser which skips blank lines rather than
+// stopping at the first one, as arbitrary files may have them anywhere.
+func createAsMapParser(lineParser lineParserFn) func(io.Reader) (map[string]interface{}, error) {
+	return func(r io.Reader) (map[string]interface{}, error) {
+		lines, err := readAllLines(r)
+		if err != nil {
+			return nil, err
+		}
+
+		result := make(map[string]interface{})
+		for _, line := range lines {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			key, val, err := lineParser(line)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = val
+		}
+
+		return result, nil
+	}
+}
+
+// skipRows skips the first n rows of r.
+func skipRows(r io.Reader, n int) (io.Reader, error) {
+	if n <= 0 {
+		return r, nil
+	}
+
+	lines, err := readAllLines(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) < n {
+		n = len(lines)
+	}
+	return strings.NewReader(strings.Join(lines[n:], "\n")), nil
+}
+
+func asLineSplitter(separator string) (lineSplitterFn, error) {
+	switch separator {
+	case "", "colon":
+		return splitLineByFirstColon, nil
+	case "space":
+		return splitLineByFirstSpace, nil
+	case "equals":
+		return splitLineByFirstEquals, nil
+	}
+	return nil, errors.Errorf("unknown separator: %s", separator)
+}
+
+func asColumnSplitter(separator string) (tableColumnSplitterFn, error) {
+	switch separator {
+	case "", "space":
+		return splitColumnsBySpace, nil
+	case "colon":
+		return func(row string) ([]string, error) {
+			return strings.Split(row, ":"), nil
+		}, nil
+	case "equals":
+		return func(row string) ([]string, error) {
+			return strings.Split(row, "="), nil
+		}, nil
+	}
+	return nil, errors.Errorf("unknown separator: %s", separator)
+}
+
+// createAsColumnsParser creates a columns parser which names each column after
+// the corresponding column of the header row if any, or column1, column2, ...
+// otherwise.
+func createAsColumnsParser(splitter tableColumnSplitterFn) tableColumnsParserFn {
+	return func(header, columns []string) (map[string]interface{}, error) {
+		var keys []string
+		if len(header) > 0 {
+			var err error
+			keys, err = splitter(header[0])
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		result := make(map[string]interface{})
+		for i, col := range columns {
+			key := fmt.Sprintf("column%d", i+1)
+			if i < len(keys) && strings.TrimSpace(keys[i]) != "" {
+				key = strings.TrimSpace(keys[i])
+			}
+			result[key] = parseIntegerOrString(strings.TrimSpace(col))
+		}
+		return result, nil
+	}
+}
+
+func parseAsValue(_, valueStr string) (interface{}, error) {
+	return parseIntegerOrString(valueStr), nil
+}
+
+func parseAsLines(r io.Reader) ([]interface{}, error) {
+	lines, err := readAllLines(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	result := []interface{}{}
+	for _, line := range lines {
+		result = append(result, line)
+	}
+	return result, nil
+}
+
+func parseAsRaw(r io.Reader) (interface{}, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// splitLineByFirstSpace splits a line at its first run of white spaces.
+func splitLineByFirstSpace(line string) (string, string, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", "", errors.New("empty string where space separated string is expected")
+	}
+	return fields[0], strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), fields[0])), nil
+}
+
+// splitLineByFirstEquals splits a line only at its first equal sign (e.g.
+// "KEY=a=b").
+func splitLineByFirstEquals(line string) (string, string, error) {
+	key, val, _ := strings.Cut(line, "=")
+	return strings.TrimSpace(key), strings.TrimSpace(val), nil
+}
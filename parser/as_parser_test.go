@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseAs(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  Options
+		want  interface{}
+	}{
+		{
+			name:  "kv skips blank lines",
+			input: "a=1\nb=x\n\nc=3\n",
+			opts:  Options{As: "kv", Separator: "equals"},
+			want:  map[string]interface{}{"a": int64(1), "b": "x", "c": int64(3)},
+		},
+		{
+			name:  "chunks without trailing blank line",
+			input: "a: 1\nb: 2",
+			opts:  Options{As: "chunks"},
+			want: []interface{}{
+				map[string]interface{}{"a": int64(1), "b": int64(2)},
+			},
+		},
+		{
+			name:  "chunks separated by blank lines",
+			input: "a: 1\n\na: 2\nb: x\n",
+			opts:  Options{As: "chunks"},
+			want: []interface{}{
+				map[string]interface{}{"a": int64(1)},
+				map[string]interface{}{"a": int64(2), "b": "x"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			got, err := parseAs(strings.NewReader(tt.input), &opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
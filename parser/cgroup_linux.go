@@ -0,0 +1,368 @@
+//go:build linux
+
+package parser
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type cgroupFileParserFn func(io.Reader) (interface{}, error)
+
+// cgroupFileParsers holds the parsers of the cgroup v2 and v1 controller files
+// by their file names.
+var cgroupFileParsers = map[string]cgroupFileParserFn{
+	// cgroup v2
+	"cgroup.controllers":     parseCgroupStringList,
+	"cgroup.events":          parseCgroupFlatKeyed,
+	"cgroup.max.depth":       parseCgroupMaxOrInteger,
+	"cgroup.max.descendants": parseCgroupMaxOrInteger,
+	"cgroup.procs":           parseCgroupIntegerList,
+	"cgroup.stat":            parseCgroupFlatKeyed,
+	"cgroup.subtree_control": parseCgroupStringList,
+	"cgroup.threads":         parseCgroupIntegerList,
+	"cgroup.type":            parseCgroupString,
+	"cpu.max":                parseCgroupCPUMax,
+	"cpu.pressure":           parseCgroupPressure,
+	"cpu.stat":               parseCgroupFlatKeyed,
+	"cpu.stat.local":         parseCgroupFlatKeyed,
+	"cpu.weight":             parseCgroupInteger,
+	"cpu.weight.nice":        parseCgroupInteger,
+	"cpuset.cpus":            parseCgroupRangeList,
+	"cpuset.cpus.effective":  parseCgroupRangeList,
+	"cpuset.mems":            parseCgroupRangeList,
+	"cpuset.mems.effective":  parseCgroupRangeList,
+	"io.max":                 parseCgroupNestedKeyed,
+	"io.pressure":            parseCgroupPressure,
+	"io.stat":                parseCgroupNestedKeyed,
+	"memory.current":         parseCgroupMaxOrInteger,
+	"memory.events":          parseCgroupFlatKeyed,
+	"memory.high":            parseCgroupMaxOrInteger,
+	"memory.low":             parseCgroupMaxOrInteger,
+	"memory.max":             parseCgroupMaxOrInteger,
+	"memory.min":             parseCgroupMaxOrInteger,
+	"memory.peak":            parseCgroupMaxOrInteger,
+	"memory.pressure":        parseCgroupPressure,
+	"memory.stat":            parseCgroupFlatKeyed,
+	"memory.swap.current":    parseCgroupMaxOrInteger,
+	"memory.swap.max":        parseCgroupMaxOrInteger,
+	"pids.current":           parseCgroupMaxOrInteger,
+	"pids.events":            parseCgroupFlatKeyed,
+	"pids.max":               parseCgroupMaxOrInteger,
+	"pids.peak":              parseCgroupMaxOrInteger,
+
+	// cgroup v1
+	"blkio.throttle.io_service_bytes": parseCgroupBlkioStat,
+	"blkio.throttle.io_serviced":      parseCgroupBlkioStat,
+	"cpu.cfs_period_us":               parseCgroupInteger,
+	"cpu.cfs_quota_us":                parseCgroupInteger,
+	"cpu.shares":                      parseCgroupInteger,
+	"cpuacct.stat":                    parseCgroupFlatKeyed,
+	"cpuacct.usage":                   parseCgroupInteger,
+	"cpuacct.usage_percpu":            parseCgroupIntegerList,
+	"cpuacct.usage_sys":               parseCgroupInteger,
+	"cpuacct.usage_user":              parseCgroupInteger,
+	"cpuset.effective_cpus":           parseCgroupRangeList,
+	"cpuset.effective_mems":           parseCgroupRangeList,
+	"memory.failcnt":                  parseCgroupInteger,
+	"memory.limit_in_bytes":           parseCgroupInteger,
+	"memory.max_usage_in_bytes":       parseCgroupInteger,
+	"memory.memsw.limit_in_bytes":     parseCgroupInteger,
+	"memory.memsw.usage_in_bytes":     parseCgroupInteger,
+	"memory.oom_control":              parseCgroupFlatKeyed,
+	"memory.soft_limit_in_bytes":      parseCgroupInteger,
+	"memory.swappiness":               parseCgroupInteger,
+	"memory.usage_in_bytes":           parseCgroupInteger,
+	"tasks":                           parseCgroupIntegerList,
+}
+
+// parseCgroupDir reads the known controller files of a cgroup directory into
+// an object nested by controller (e.g. "memory.current" becomes
+// .memory.current). Both cgroup v2 and v1 hierarchies are supported.
+func parseCgroupDir(dir string) (interface{}, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	version := int64(1)
+	result := map[string]interface{}{
+		"path": dir,
+	}
+	children := []interface{}{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			children = append(children, name)
+			continue
+		}
+
+		if name == "cgroup.controllers" {
+			version = 2
+		}
+
+		if _, ok := cgroupFileParsers[name]; !ok {
+			continue
+		}
+
+		val, err := parseCgroupFile(filepath.Join(dir, name))
+		if err != nil {
+			var pathErr *os.PathError
+			// skip the files which cannot be read, e.g. due to permissions
+			if errors.As(err, &pathErr) {
+				continue
+			}
+			return nil, err
+		}
+
+		controller, key, ok := strings.Cut(name, ".")
+		if !ok {
+			result[name] = val
+			continue
+		}
+		m, _ := result[controller].(map[string]interface{})
+		if m == nil {
+			m = make(map[string]interface{})
+			result[controller] = m
+		}
+		m[key] = val
+	}
+	result["version"] = version
+	result["children"] = children
+
+	return result, nil
+}
+
+// parseCgroupFile reads a controller file by its parser. Files which have no
+// parser are read as in directories which have no dedicated format, i.e. as a
+// single value.
+func parseCgroupFile(fname string) (interface{}, error) {
+	parser, ok := cgroupFileParsers[filepath.Base(fname)]
+	if !ok {
+		return readSysfsTypedAttr(fname)
+	}
+
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	val, err := parser(f)
+	if err != nil {
+		return nil, errors.Wrap(err, fname)
+	}
+	return val, nil
+}
+
+func readTrimmed(r io.Reader) (string, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+func parseCgroupString(r io.Reader) (interface{}, error) {
+	return readTrimmed(r)
+}
+
+func parseCgroupInteger(r io.Reader) (interface{}, error) {
+	s, err := readTrimmed(r)
+	if err != nil {
+		return nil, err
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+func parseCgroupMaxOrInteger(r io.Reader) (interface{}, error) {
+	s, err := readTrimmed(r)
+	if err != nil {
+		return nil, err
+	}
+	return sentinelOrInteger(s, "max")
+}
+
+func parseCgroupRangeList(r io.Reader) (interface{}, error) {
+	s, err := readTrimmed(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseRangeList(s)
+}
+
+func parseCgroupStringList(r io.Reader) (interface{}, error) {
+	s, err := readTrimmed(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []interface{}{}
+	for _, field := range strings.Fields(s) {
+		result = append(result, field)
+	}
+	return result, nil
+}
+
+func parseCgroupIntegerList(r io.Reader) (interface{}, error) {
+	s, err := readTrimmed(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []interface{}{}
+	for _, field := range strings.Fields(s) {
+		val, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, val)
+	}
+	return result, nil
+}
+
+// parseCgroupFlatKeyed parses files such as memory.stat which consist of
+// lines of "key value".
+func parseCgroupFlatKeyed(r io.Reader) (interface{}, error) {
+	lines, err := readAllLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	for _, line := range lines {
+		columns, _ := splitColumnsBySpace(line)
+		if len(columns) == 0 {
+			continue
+		}
+		if len(columns) != 2 {
+			return nil, errors.Errorf("unknown flat keyed format: %s", line)
+		}
+		result[columns[0]] = parseNumberOrString(columns[1])
+	}
+	return result, nil
+}
+
+// parseCgroupNestedKeyed parses files such as io.stat which consist of lines
+// of "key subkey=value subkey=value ...".
+func parseCgroupNestedKeyed(r io.Reader) (interface{}, error) {
+	lines, err := readAllLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	for _, line := range lines {
+		key, pairs, _ := strings.Cut(strings.TrimSpace(line), " ")
+		if key == "" {
+			continue
+		}
+		result[key] = parseKeyEqualsValuePairs(pairs)
+	}
+	return result, nil
+}
+
+// parseCgroupPressure parses pressure stall information such as
+// "some avg10=0.00 avg60=0.00 avg300=0.00 total=0".
+func parseCgroupPressure(r io.Reader) (interface{}, error) {
+	return parseCgroupNestedKeyed(r)
+}
+
+// parseCgroupCPUMax parses cpu.max such as "max 100000".
+func parseCgroupCPUMax(r io.Reader) (interface{}, error) {
+	s, err := readTrimmed(r)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, _ := splitColumnsBySpace(s)
+	if len(columns) != 2 {
+		return nil, errors.Errorf("unknown cpu.max format: %s", s)
+	}
+
+	quota, err := sentinelOrInteger(columns[0], "max")
+	if err != nil {
+		return nil, err
+	}
+	period, err := strconv.ParseInt(columns[1], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"quota":  quota,
+		"period": period,
+	}, nil
+}
+
+// parseCgroupBlkioStat parses cgroup v1 blkio statistics such as
+// "8:0 Read 1234" followed by "Total 1234".
+func parseCgroupBlkioStat(r io.Reader) (interface{}, error) {
+	lines, err := readAllLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	for _, line := range lines {
+		columns, _ := splitColumnsBySpace(line)
+		switch len(columns) {
+		case 0:
+			continue
+		case 2:
+			result[strings.ToLower(columns[0])], err = strconv.ParseInt(columns[1], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+		case 3:
+			val, err := strconv.ParseInt(columns[2], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			dev, _ := result[columns[0]].(map[string]interface{})
+			if dev == nil {
+				dev = make(map[string]interface{})
+				result[columns[0]] = dev
+			}
+			dev[strings.ToLower(columns[1])] = val
+		default:
+			return nil, errors.Errorf("unknown blkio format: %s", line)
+		}
+	}
+	return result, nil
+}
+
+func parseProcPidCgroupColumns(_ []string, columns []string) (map[string]interface{}, error) {
+	if len(columns) < 3 {
+		return nil, errors.Errorf("unknown /proc/[pid]/cgroup format. expected 3 columns but got %d columns", len(columns))
+	}
+
+	id, err := strconv.ParseInt(columns[0], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	controllers := []interface{}{}
+	if columns[1] != "" {
+		for _, c := range strings.Split(columns[1], ",") {
+			controllers = append(controllers, c)
+		}
+	}
+
+	return map[string]interface{}{
+		"hierarchy_id": id,
+		"controllers":  controllers,
+		"path":         columns[2],
+	}, nil
+}
+
+// splitProcPidCgroupColumns splits "hierarchy-ID:controller-list:cgroup-path"
+// keeping colons in the path.
+func splitProcPidCgroupColumns(row string) ([]string, error) {
+	return strings.SplitN(row, ":", 3), nil
+}
@@ -0,0 +1,109 @@
+//go:build linux
+
+package parser
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseCgroupDirV2(t *testing.T) {
+	root := writeSysfsFixture(t, map[string]string{
+		"user.slice/cgroup.controllers":           "cpu io memory pids\n",
+		"user.slice/cgroup.procs":                 "1\n42\n",
+		"user.slice/cpu.max":                      "max 100000\n",
+		"user.slice/cpu.pressure":                 "some avg10=0.00 avg60=0.50 avg300=0.00 total=1234\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=0\n",
+		"user.slice/cpuset.cpus.effective":        "0-3\n",
+		"user.slice/io.stat":                      "8:0 rbytes=1024 wbytes=0 rios=1 wios=0 dbytes=0 dios=0\n",
+		"user.slice/memory.current":               "4096\n",
+		"user.slice/memory.max":                   "max\n",
+		"user.slice/memory.stat":                  "anon 1024\nfile 2048\n",
+		"user.slice/unknown.file":                 "skipped\n",
+		"user.slice/user-1000.slice/cgroup.procs": "",
+	})
+	dir := filepath.Join(root, "user.slice")
+
+	got, err := parseCgroupDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"path":     dir,
+		"version":  int64(2),
+		"children": []interface{}{"user-1000.slice"},
+		"cgroup": map[string]interface{}{
+			"controllers": []interface{}{"cpu", "io", "memory", "pids"},
+			"procs":       []interface{}{int64(1), int64(42)},
+		},
+		"cpu": map[string]interface{}{
+			"max": map[string]interface{}{"quota": "max", "period": int64(100000)},
+			"pressure": map[string]interface{}{
+				"some": map[string]interface{}{"avg10": 0.0, "avg60": 0.5, "avg300": 0.0, "total": int64(1234)},
+				"full": map[string]interface{}{"avg10": 0.0, "avg60": 0.0, "avg300": 0.0, "total": int64(0)},
+			},
+		},
+		"cpuset": map[string]interface{}{
+			"cpus.effective": []interface{}{int64(0), int64(1), int64(2), int64(3)},
+		},
+		"io": map[string]interface{}{
+			"stat": map[string]interface{}{
+				"8:0": map[string]interface{}{"rbytes": int64(1024), "wbytes": int64(0), "rios": int64(1), "wios": int64(0), "dbytes": int64(0), "dios": int64(0)},
+			},
+		},
+		"memory": map[string]interface{}{
+			"current": int64(4096),
+			"max":     "max",
+			"stat":    map[string]interface{}{"anon": int64(1024), "file": int64(2048)},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestParseCgroupDirV1(t *testing.T) {
+	root := writeSysfsFixture(t, map[string]string{
+		"docker/tasks":                      "1\n2\n",
+		"docker/cpu.cfs_quota_us":           "-1\n",
+		"docker/cpu.cfs_period_us":          "100000\n",
+		"docker/cpuacct.usage_percpu":       "100 200 \n",
+		"docker/memory.limit_in_bytes":      "9223372036854771712\n",
+		"docker/memory.oom_control":         "oom_kill_disable 0\nunder_oom 0\noom_kill 0\n",
+		"docker/blkio.throttle.io_serviced": "8:0 Read 10\n8:0 Write 5\n8:0 Sync 12\n8:0 Async 3\n8:0 Discard 0\n8:0 Total 15\nTotal 15\n",
+	})
+	dir := filepath.Join(root, "docker")
+
+	got, err := parseCgroupDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"path":     dir,
+		"version":  int64(1),
+		"children": []interface{}{},
+		"tasks":    []interface{}{int64(1), int64(2)},
+		"cpu": map[string]interface{}{
+			"cfs_quota_us":  int64(-1),
+			"cfs_period_us": int64(100000),
+		},
+		"cpuacct": map[string]interface{}{
+			"usage_percpu": []interface{}{int64(100), int64(200)},
+		},
+		"memory": map[string]interface{}{
+			"limit_in_bytes": int64(9223372036854771712),
+			"oom_control":    map[string]interface{}{"oom_kill_disable": int64(0), "under_oom": int64(0), "oom_kill": int64(0)},
+		},
+		"blkio": map[string]interface{}{
+			"throttle.io_serviced": map[string]interface{}{
+				"8:0":   map[string]interface{}{"read": int64(10), "write": int64(5), "sync": int64(12), "async": int64(3), "discard": int64(0), "total": int64(15)},
+				"total": int64(15),
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,96 @@
+//go:build ignore
+
+// gen_syscall_names generates syscall_names_linux_<arch>.go from the
+// zsysnum_linux_<arch>.go files of golang.org/x/sys/unix at the version in
+// go.mod. Run it by go generate.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var archs = []string{"386", "amd64", "arm", "arm64", "loong64", "mips", "mipsle", "mips64", "mips64le", "ppc64", "ppc64le", "riscv64", "s390x"}
+
+// notSyscalls are the constants in zsysnum_linux_<arch>.go which are not
+// system calls but bases or masks of their numbers.
+var notSyscalls = map[string]bool{
+	"SYS_SYSCALL_MASK":          true,
+	"SYS_ARCH_SPECIFIC_SYSCALL": true,
+}
+
+var reSysnum = regexp.MustCompile(`^\s*(SYS_[A-Z0-9_]+)\s*=\s*(\d+)$`)
+
+func main() {
+	out, err := exec.Command("go", "list", "-m", "-f", "{{.Dir}}", "golang.org/x/sys").Output()
+	if err != nil {
+		log.Fatal(err)
+	}
+	dir := filepath.Join(strings.TrimSpace(string(out)), "unix")
+
+	for _, arch := range archs {
+		if err := generate(dir, arch); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func generate(dir, arch string) error {
+	src := "zsysnum_linux_" + arch + ".go"
+	f, err := os.Open(filepath.Join(dir, src))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	names := make(map[int64]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		submatch := reSysnum.FindStringSubmatch(scanner.Text())
+		if submatch == nil || notSyscalls[submatch[1]] {
+			continue
+		}
+		nr, err := strconv.ParseInt(submatch[2], 10, 64)
+		if err != nil {
+			return err
+		}
+		if _, ok := names[nr]; !ok {
+			names[nr] = strings.ToLower(strings.TrimPrefix(submatch[1], "SYS_"))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	nrs := make([]int64, 0, len(names))
+	for nr := range names {
+		nrs = append(nrs, nr)
+	}
+	sort.Slice(nrs, func(i, j int) bool { return nrs[i] < nrs[j] })
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by gen_syscall_names.go from golang.org/x/sys/unix/%s. DO NOT EDIT.\n\n", src)
+	fmt.Fprintf(&buf, "package parser\n\n")
+	fmt.Fprintf(&buf, "// syscallNames maps the system call numbers of the architecture to their names\n")
+	fmt.Fprintf(&buf, "var syscallNames = map[int64]string{\n")
+	for _, nr := range nrs {
+		fmt.Fprintf(&buf, "\t%d: %q,\n", nr, names[nr])
+	}
+	fmt.Fprintf(&buf, "}\n")
+
+	b, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	return os.WriteFile("syscall_names_linux_"+arch+".go", b, 0644)
+}
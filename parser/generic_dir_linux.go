@@ -0,0 +1,142 @@
+//go:build linux
+
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// genericDirBlockingFiles are the names of files which block until data
+// arrives and consume what they return (e.g. /proc/kmsg and trace_pipe in
+// tracefs), so walkGenericDir does not read them at all.
+var genericDirBlockingFiles = []string{"kmsg", "trace_pipe", "trace_pipe_raw"}
+
+// parseGenericDir reads a directory which has no dedicated format, assuming
+// that each file holds a single value as in sysfs. It returns nested objects
+// of file names to values walking subdirectories up to Options.Depth. Files
+// other than directories are not supported.
+func parseGenericDir(in *Input) (interface{}, error) {
+	fi, err := os.Stat(in.Name)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, errors.Errorf("%s is not supported", in.Name)
+	}
+
+	opts := in.options()
+	return walkGenericDir(in.Name, opts.Depth, opts.FollowSymlinks)
+}
+
+// walkGenericDir reads files in dir typed by parseIntegerOrString. Files which
+// cannot be read, files known to block (genericDirBlockingFiles) and files
+// other than regular ones (e.g. FIFOs and devices, which may block or never
+// end), are null. Only the first page of each file is read. Symbolic links to directories are walked if
+// followSymlinks is true, otherwise recorded as {"symlink": <target>}.
+// Subdirectories are null when depth is exhausted, as are the ones which
+// cannot be read.
+func walkGenericDir(dir string, depth int, followSymlinks bool) (map[string]interface{}, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	for _, entry := range entries {
+		p := filepath.Join(dir, entry.Name())
+
+		isDir, isRegular := entry.IsDir(), entry.Type().IsRegular()
+		if entry.Type()&os.ModeSymlink != 0 {
+			fi, err := os.Stat(p)
+			if err != nil {
+				// dangling link
+				result[entry.Name()] = nil
+				continue
+			}
+
+			if fi.IsDir() && !followSymlinks {
+				target, err := os.Readlink(p)
+				if err != nil {
+					return nil, err
+				}
+				result[entry.Name()] = map[string]interface{}{
+					"symlink": target,
+				}
+				continue
+			}
+			isDir, isRegular = fi.IsDir(), fi.Mode().IsRegular()
+		}
+
+		if isDir {
+			if depth <= 1 {
+				result[entry.Name()] = nil
+				continue
+			}
+
+			sub, err := walkGenericDir(p, depth-1, followSymlinks)
+			if err != nil {
+				result[entry.Name()] = nil
+				continue
+			}
+			result[entry.Name()] = sub
+			continue
+		}
+
+		if !isRegular || isGenericDirBlockingFile(p) {
+			result[entry.Name()] = nil
+			continue
+		}
+
+		result[entry.Name()] = readGenericFile(p)
+	}
+
+	return result, nil
+}
+
+// isGenericDirBlockingFile returns true if p, or the file p links to, is one
+// of genericDirBlockingFiles.
+func isGenericDirBlockingFile(p string) bool {
+	if containsString(genericDirBlockingFiles, filepath.Base(p)) {
+		return true
+	}
+	target, err := filepath.EvalSymlinks(p)
+	return err == nil && containsString(genericDirBlockingFiles, filepath.Base(target))
+}
+
+// readGenericFile reads up to a page of p without blocking and types it by
+// parseIntegerOrString, or returns nil if p cannot be read. The file is read
+// by raw system calls rather than os.File, which would wait in the poller for
+// files supporting poll (e.g. /proc/kmsg) even if opened with O_NONBLOCK.
+func readGenericFile(p string) interface{} {
+	fd, err := syscall.Open(p, syscall.O_RDONLY|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil
+	}
+	defer syscall.Close(fd)
+
+	buf := make([]byte, os.Getpagesize())
+	size := 0
+	for size < len(buf) {
+		n, err := syscall.Read(fd, buf[size:])
+		if err == syscall.EINTR {
+			continue
+		}
+		if err == syscall.EAGAIN {
+			break
+		}
+		if err != nil {
+			return nil
+		}
+		if n == 0 {
+			break
+		}
+		size += n
+	}
+
+	return parseIntegerOrString(strings.TrimRight(string(buf[:size]), "\n"))
+}
@@ -0,0 +1,135 @@
+//go:build linux
+
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// withTimeout fails the test if f does not return in a few seconds, e.g.
+// because it blocks reading a FIFO.
+func withTimeout(t *testing.T, f func()) {
+	t.Helper()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out")
+	}
+}
+
+func TestWalkGenericDirNonRegularFiles(t *testing.T) {
+	root := writeSysfsFixture(t, map[string]string{
+		"dir/value":   "42\n",
+		"dir/fifo_ln": "->fifo",
+		"dir/kmsg":    "a message\n",
+	})
+	if err := syscall.Mkfifo(filepath.Join(root, "dir", "fifo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// keep a writer open so that a blocking read of the FIFO never ends
+	w, err := os.OpenFile(filepath.Join(root, "dir", "fifo"), os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	var got map[string]interface{}
+	withTimeout(t, func() {
+		got, err = walkGenericDir(filepath.Join(root, "dir"), 1, true)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"value":   int64(42),
+		"fifo":    nil,
+		"fifo_ln": nil,
+		"kmsg":    nil,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestReadGenericFile(t *testing.T) {
+	root := writeSysfsFixture(t, map[string]string{
+		"large": string(make([]byte, 3*os.Getpagesize())),
+	})
+	fifo := filepath.Join(root, "fifo")
+	if err := syscall.Mkfifo(fifo, 0644); err != nil {
+		t.Fatal(err)
+	}
+	w, err := os.OpenFile(fifo, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	withTimeout(t, func() {
+		if got := readGenericFile(fifo); got != "" {
+			t.Errorf("got %#v, want empty string", got)
+		}
+	})
+
+	if got := readGenericFile(filepath.Join(root, "large")).(string); len(got) != os.Getpagesize() {
+		t.Errorf("got %d bytes, want %d bytes", len(got), os.Getpagesize())
+	}
+}
+
+func TestWalkGenericDirDepth(t *testing.T) {
+	root := writeSysfsFixture(t, map[string]string{
+		"dir/value":       "1\n",
+		"dir/sub/value":   "2\n",
+		"dir/sub/sub/foo": "bar\n",
+	})
+
+	tests := []struct {
+		depth int
+		want  map[string]interface{}
+	}{
+		{
+			depth: 1,
+			want:  map[string]interface{}{"value": int64(1), "sub": nil},
+		},
+		{
+			depth: 2,
+			want: map[string]interface{}{
+				"value": int64(1),
+				"sub":   map[string]interface{}{"value": int64(2), "sub": nil},
+			},
+		},
+		{
+			depth: 3,
+			want: map[string]interface{}{
+				"value": int64(1),
+				"sub": map[string]interface{}{
+					"value": int64(2),
+					"sub":   map[string]interface{}{"foo": "bar"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := walkGenericDir(filepath.Join(root, "dir"), tt.depth, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("depth %d: got %#v, want %#v", tt.depth, got, tt.want)
+		}
+	}
+}
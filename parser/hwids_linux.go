@@ -0,0 +1,194 @@
+//go:build linux
+
+package parser
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+)
+
+// pciIdsPaths and usbIdsPaths are the locations where distributions install
+// the ID databases (https://pci-ids.ucw.cz/ and http://www.linux-usb.org/).
+var (
+	pciIdsPaths = []string{
+		"/usr/share/hwdata/pci.ids",
+		"/usr/share/misc/pci.ids",
+		"/usr/share/pci.ids",
+		"/usr/share/misc/pci.ids.gz",
+	}
+	usbIdsPaths = []string{
+		"/usr/share/hwdata/usb.ids",
+		"/usr/share/misc/usb.ids",
+		"/usr/share/usb.ids",
+		"/var/lib/usbutils/usb.ids",
+		"/usr/share/misc/usb.ids.gz",
+	}
+)
+
+// hwids holds the names in pci.ids or usb.ids. The keys are lower case hex
+// IDs joined by colons, e.g. "8086" for a vendor, "8086:1533" for a device
+// and "8086:1533:15d9:1533" for a subsystem. Classes are keyed by class,
+// class and subclass, and class, subclass and prog-if, e.g. "02", "0200" and
+// "020000".
+type hwids struct {
+	vendors    map[string]string
+	devices    map[string]string
+	subsystems map[string]string
+	classes    map[string]string
+}
+
+// loadHwids loads the first ID database found in paths. nil is returned if
+// there is none, and lookups on it return nil.
+func loadHwids(paths []string) (*hwids, error) {
+	for _, p := range paths {
+		f, err := os.Open(p)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		defer f.Close()
+
+		var r io.Reader = f
+		if strings.HasSuffix(p, ".gz") {
+			gz, err := gzip.NewReader(f)
+			if err != nil {
+				return nil, err
+			}
+			defer gz.Close()
+			r = gz
+		}
+
+		return parseHwids(r)
+	}
+
+	return nil, nil
+}
+
+// parseHwids parses the format of pci.ids and usb.ids, where devices are
+// indented by a tab under their vendor and subsystems by two tabs under their
+// device. Classes are in sections starting with "C". Other sections of
+// usb.ids (e.g. "HID") are skipped.
+func parseHwids(r io.Reader) (*hwids, error) {
+	ids := &hwids{
+		vendors:    make(map[string]string),
+		devices:    make(map[string]string),
+		subsystems: make(map[string]string),
+		classes:    make(map[string]string),
+	}
+
+	var section, vendor, device, class, subclass string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		depth := len(line) - len(strings.TrimLeft(line, "\t"))
+		id, name, _ := strings.Cut(strings.TrimLeft(line, "\t"), "  ")
+		id = strings.ToLower(id)
+
+		switch depth {
+		case 0:
+			if strings.HasPrefix(id, "c ") {
+				section = "class"
+				class = strings.TrimPrefix(id, "c ")
+				ids.classes[class] = name
+			} else if len(id) == 4 && isHexString(id) {
+				section = "vendor"
+				vendor = id
+				ids.vendors[vendor] = name
+			} else {
+				section = ""
+			}
+
+		case 1:
+			switch section {
+			case "vendor":
+				device = vendor + ":" + id
+				ids.devices[device] = name
+			case "class":
+				subclass = class + id
+				ids.classes[subclass] = name
+			}
+
+		case 2:
+			switch section {
+			case "vendor":
+				ids.subsystems[device+":"+strings.Join(strings.Fields(id), ":")] = name
+			case "class":
+				ids.classes[subclass+id] = name
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (ids *hwids) vendor(vendor string) interface{} {
+	if ids == nil {
+		return nil
+	}
+	return lookupHwid(ids.vendors, vendor)
+}
+
+func (ids *hwids) device(vendor, device string) interface{} {
+	if ids == nil {
+		return nil
+	}
+	return lookupHwid(ids.devices, vendor, device)
+}
+
+func (ids *hwids) subsystem(vendor, device, subvendor, subdevice string) interface{} {
+	if ids == nil {
+		return nil
+	}
+	return lookupHwid(ids.subsystems, vendor, device, subvendor, subdevice)
+}
+
+// class returns the name of the subclass of a 6-digit class code such as
+// "020000" (i.e. "Ethernet controller"), or the name of the class if the
+// subclass is unknown.
+func (ids *hwids) class(class string) interface{} {
+	if ids == nil || len(class) < 4 {
+		return nil
+	}
+	if name := lookupHwid(ids.classes, class[:4]); name != nil {
+		return name
+	}
+	return lookupHwid(ids.classes, class[:2])
+}
+
+// progIf returns the name of the programming interface of a 6-digit class
+// code such as "0c0330" (i.e. "XHCI"), or nil if it has none.
+func (ids *hwids) progIf(class string) interface{} {
+	if ids == nil || len(class) < 6 {
+		return nil
+	}
+	return lookupHwid(ids.classes, class[:6])
+}
+
+func lookupHwid(names map[string]string, id ...string) interface{} {
+	name, ok := names[strings.ToLower(strings.Join(id, ":"))]
+	if !ok {
+		return nil
+	}
+	return name
+}
+
+func isHexString(s string) bool {
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,85 @@
+//go:build linux
+
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+const testPciIds = `# pci.ids fixture
+8086  Intel Corporation
+	1533  I210 Gigabit Network Connection
+		15d9 1533  I210 Gigabit Network Connection
+10de  NVIDIA Corporation
+C 02  Network controller
+	00  Ethernet controller
+C 0c  Serial bus controller
+	03  USB controller
+		20  EHCI
+		30  XHCI
+C ff  Unassigned class
+`
+
+const testUsbIds = `1d6b  Linux Foundation
+	0002  2.0 root hub
+	0003  3.0 root hub
+C 09  Hub
+HID 00  None
+`
+
+func TestParseHwids(t *testing.T) {
+	ids, err := parseHwids(strings.NewReader(testPciIds))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"vendor", ids.vendor("8086"), "Intel Corporation"},
+		{"vendor in upper case", ids.vendor("10DE"), "NVIDIA Corporation"},
+		{"unknown vendor", ids.vendor("1234"), nil},
+		{"device", ids.device("8086", "1533"), "I210 Gigabit Network Connection"},
+		{"unknown device", ids.device("10de", "1533"), nil},
+		{"subsystem", ids.subsystem("8086", "1533", "15d9", "1533"), "I210 Gigabit Network Connection"},
+		{"unknown subsystem", ids.subsystem("8086", "1533", "15d9", "0000"), nil},
+		{"subclass", ids.class("020000"), "Ethernet controller"},
+		{"class of unknown subclass", ids.class("ff0100"), "Unassigned class"},
+		{"unknown class", ids.class("130000"), nil},
+		{"prog-if", ids.progIf("0c0330"), "XHCI"},
+		{"unknown prog-if", ids.progIf("0c0340"), nil},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %#v, want %#v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestParseHwidsUsb(t *testing.T) {
+	ids, err := parseHwids(strings.NewReader(testUsbIds))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := ids.device("1d6b", "0003"); got != "3.0 root hub" {
+		t.Errorf("got %#v, want %#v", got, "3.0 root hub")
+	}
+	if got := ids.class("090000"); got != "Hub" {
+		t.Errorf("got %#v, want %#v", got, "Hub")
+	}
+	// sections other than vendors and classes are skipped
+	if got := ids.vendor("00"); got != nil {
+		t.Errorf("got %#v, want nil", got)
+	}
+}
+
+func TestNilHwids(t *testing.T) {
+	var ids *hwids
+	if ids.vendor("8086") != nil || ids.device("8086", "1533") != nil || ids.class("020000") != nil || ids.progIf("0c0330") != nil {
+		t.Error("lookups on nil hwids should return nil")
+	}
+}
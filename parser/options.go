@@ -0,0 +1,38 @@
+package parser
+
+// Options are the options of ParseWithOptions. The zero value parses files
+// as Parse does.
+type Options struct {
+	// QueryFriendly replaces white spaces and parentheses in field names
+	// with '_' and makes them lower case (e.g. "cpu MHz" to "cpu_mhz").
+	// Only the keys of the top-level object, or of the objects in the
+	// top-level array, are field names; nested keys are kept as they are.
+	QueryFriendly bool
+	// Flat flattens nested entries (e.g. /proc/iomem) into an array with
+	// depth field.
+	Flat bool
+	// ModuleParameters joins parameters in /sys/module into each row of
+	// /proc/modules.
+	ModuleParameters bool
+	// Depth is the maximum depth to walk directories which have no
+	// dedicated format. Zero is the same as 1, i.e. only the files in the
+	// directory are read. Subdirectories beyond the depth are null.
+	Depth int
+	// FollowSymlinks follows symbolic links to directories instead of
+	// recording their targets.
+	FollowSymlinks bool
+
+	// As parses the file generically instead of by a dedicated format. It
+	// is one of "kv", "table", "lines", "chunks" and "raw".
+	As string
+	// Separator is the separator of keys and values (or columns) for As.
+	// It is one of "colon", "space" and "equals" (default: colon, or space
+	// for table).
+	Separator string
+	// SkipRows is the number of rows to skip at the beginning of the file
+	// for As.
+	SkipRows int
+	// Header uses the first row (after SkipRows) as column names for As
+	// table.
+	Header bool
+}
@@ -0,0 +1,458 @@
+package parser
+
+import (
+	"io"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// makeQueryFriendly replaces white spaces and parentheses in key with '_' and
+// makes it lower case (e.g. "cpu MHz" to "cpu_mhz").
+func makeQueryFriendly(key string) string {
+	return strings.ToLower(
+		strings.ReplaceAll(
+			strings.ReplaceAll(
+				strings.ReplaceAll(key, " ", "_"),
+				"(", "_"),
+			")", ""),
+	)
+}
+
+// makeKeysQueryFriendly applies makeQueryFriendly to the field names of v,
+// i.e. the keys of v if it is an object, or the keys of the objects in v if
+// it is an array (e.g. the rows of a table). Nested objects are kept as they
+// are since their keys are mostly data rather than field names.
+func makeKeysQueryFriendly(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			result[makeQueryFriendly(key)] = val
+		}
+		return result
+
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, val := range v {
+			if m, ok := val.(map[string]interface{}); ok {
+				val = makeKeysQueryFriendly(m)
+			}
+			result[i] = val
+		}
+		return result
+	}
+
+	return v
+}
+
+func createMapParser(lineParser lineParserFn) func(io.Reader) (map[string]interface{}, error) {
+	return func(r io.Reader) (map[string]interface{}, error) {
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+
+		result := make(map[string]interface{})
+		lines := strings.Split(string(b), "\n")
+		for _, line := range lines {
+			if line == "" {
+				break
+			}
+			key, val, err := lineParser(line)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = val
+		}
+
+		return result, nil
+	}
+}
+
+type tableHeaderParserFn func(rows []string) ([]string, []string, error)
+
+var noTableHeader tableHeaderParserFn = nil
+
+func skipTableHeader(nRows int) tableHeaderParserFn {
+	return func(rows []string) ([]string, []string, error) {
+		if len(rows) < nRows {
+			return nil, nil, errors.Errorf("unable to skip table header: %d rows to be skipped, but only %d rows available", nRows, len(rows))
+		}
+
+		return rows[:nRows], rows[nRows:], nil
+	}
+}
+
+type tableRowParserFn func(header []string, row string) (map[string]interface{}, error)
+type tableParserFn func(io.Reader) ([]interface{}, error)
+type tableColumnSplitterFn func(row string) ([]string, error)
+type tableColumnsParserFn func(header, columns []string) (map[string]interface{}, error)
+
+func createTableParser(headerParser tableHeaderParserFn, rowParser tableRowParserFn) tableParserFn {
+	return func(r io.Reader) ([]interface{}, error) {
+		lines, err := readAllLines(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var header, remaining []string
+		if headerParser != nil && len(lines) > 0 {
+			header, remaining, err = headerParser(lines)
+			if err != nil {
+				return nil, err
+			}
+			lines = remaining
+		}
+
+		var result []interface{}
+		for _, line := range lines {
+			if line == "" {
+				continue
+			}
+			row, err := rowParser(header, line)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, row)
+		}
+
+		return result, nil
+	}
+}
+
+func createTableRowParser(columnSplitter tableColumnSplitterFn, columnsParser tableColumnsParserFn) tableRowParserFn {
+	return func(header []string, row string) (map[string]interface{}, error) {
+		columns, err := columnSplitter(row)
+		if err != nil {
+			return nil, err
+		}
+
+		return columnsParser(header, columns)
+	}
+}
+
+// createHeaderKeyedColumnsParser creates a columns parser which names each
+// column after the corresponding column of the first header row.
+func createHeaderKeyedColumnsParser(valueParser valueParserFn) tableColumnsParserFn {
+	return func(header, columns []string) (map[string]interface{}, error) {
+		if len(header) < 1 {
+			return nil, errors.New("table header is required to name columns")
+		}
+
+		keys, err := splitColumnsBySpace(header[0])
+		if err != nil {
+			return nil, err
+		}
+		if len(columns) != len(keys) {
+			return nil, errors.Errorf("unexpected number of columns. expected %d columns but got %d columns", len(keys), len(columns))
+		}
+
+		result := make(map[string]interface{})
+		for i, key := range keys {
+			val, err := valueParser(key, columns[i])
+			if err != nil {
+				return nil, err
+			}
+
+			result[key] = val
+		}
+
+		return result, nil
+	}
+}
+
+func readAllLines(r io.Reader) ([]string, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Split(string(b), "\n"), nil
+}
+
+type chunkParserFn func(io.Reader) ([]interface{}, error)
+
+func createChunkParser(lineParser lineParserFn) chunkParserFn {
+	return func(r io.Reader) ([]interface{}, error) {
+		chunks, err := parseAsChunks(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var result []interface{}
+		for _, chunk := range chunks {
+			m, err := parseChunkLines(chunk, lineParser)
+			if err != nil {
+				return nil, err
+			}
+
+			result = append(result, m)
+		}
+
+		return result, nil
+	}
+}
+
+type treeParserFn func(io.Reader) ([]interface{}, error)
+type treeNodeParserFn func(line string) (map[string]interface{}, error)
+
+// createTreeParser creates a parser for files which express the hierarchy of
+// their entries by indentation (e.g. /proc/iomem). Each node gets a "children"
+// array holding the nodes indented deeper than itself.
+func createTreeParser(nodeParser treeNodeParserFn) treeParserFn {
+	return func(r io.Reader) ([]interface{}, error) {
+		lines, err := readAllLines(r)
+		if err != nil {
+			return nil, err
+		}
+
+		type level struct {
+			indent int
+			node   map[string]interface{}
+		}
+
+		result := []interface{}{}
+		var stack []level
+		for _, line := range lines {
+			trimmed := strings.TrimLeft(line, " \t")
+			if strings.TrimSpace(trimmed) == "" {
+				continue
+			}
+			indent := len(line) - len(trimmed)
+
+			node, err := nodeParser(strings.TrimSpace(trimmed))
+			if err != nil {
+				return nil, err
+			}
+			node["children"] = []interface{}{}
+
+			for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+				stack = stack[:len(stack)-1]
+			}
+			if len(stack) == 0 {
+				result = append(result, node)
+			} else {
+				parent := stack[len(stack)-1].node
+				parent["children"] = append(parent["children"].([]interface{}), node)
+			}
+			stack = append(stack, level{indent: indent, node: node})
+		}
+
+		return result, nil
+	}
+}
+
+// flattenTreeParser wraps a tree parser so that it returns the nodes in
+// depth-first order, each annotated with its depth instead of its children.
+func flattenTreeParser(parser treeParserFn) treeParserFn {
+	return func(r io.Reader) ([]interface{}, error) {
+		tree, err := parser(r)
+		if err != nil {
+			return nil, err
+		}
+
+		result := []interface{}{}
+		var flatten func(nodes []interface{}, depth int)
+		flatten = func(nodes []interface{}, depth int) {
+			for _, n := range nodes {
+				node := n.(map[string]interface{})
+				children, _ := node["children"].([]interface{})
+				delete(node, "children")
+				node["depth"] = depth
+				result = append(result, node)
+				flatten(children, depth+1)
+			}
+		}
+		flatten(tree, 0)
+
+		return result, nil
+	}
+}
+
+type lineSplitterFn func(string) (string, string, error)
+type valueParserFn func(string, string) (interface{}, error)
+type lineParserFn func(string) (string, interface{}, error)
+
+func createLineParser(splitter lineSplitterFn, valueParser valueParserFn) lineParserFn {
+	return func(line string) (string, interface{}, error) {
+		key, val, err := splitter(line)
+		if err != nil {
+			return "", "", err
+		}
+
+		valParsed, err := valueParser(key, val)
+		if err != nil {
+			return "", "", err
+		}
+
+		return key, valParsed, nil
+	}
+}
+
+type chunk []string
+
+func newChunk() chunk {
+	return []string{}
+}
+
+func parseAsChunks(r io.Reader) ([]chunk, error) {
+	lines, err := readAllLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var chunks []chunk
+	var curr chunk
+
+	for _, line := range lines {
+		if line == "" {
+			if len(curr) > 0 {
+				chunks = append(chunks, curr)
+			}
+			curr = newChunk()
+			continue
+		}
+
+		curr = append(curr, line)
+	}
+	if len(curr) > 0 {
+		chunks = append(chunks, curr)
+	}
+
+	return chunks, nil
+}
+
+func parseChunkLines(chunk chunk, lineParser func(string) (string, interface{}, error)) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	for _, line := range chunk {
+		key, value, err := lineParser(line)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+func splitLineByColon(line string) (string, string, error) {
+	return splitLineBy(line, ":")
+}
+
+// splitLineByFirstColon splits a line only at its first colon so that the
+// value may contain colons (e.g. "Name:	kworker/0:1H").
+func splitLineByFirstColon(line string) (string, string, error) {
+	key, val, _ := strings.Cut(line, ":")
+	return strings.TrimSpace(key), strings.TrimSpace(val), nil
+}
+
+func splitLineBySpace(line string) (string, string, error) {
+	return splitLineBy(line, " ")
+}
+
+func splitLineBy(line, sep string) (string, string, error) {
+	parts := strings.Split(line, sep)
+	if len(parts) < 1 {
+		return "", "", errors.New("empty string where colon separated string is expected")
+	}
+
+	key := strings.TrimSpace(parts[0])
+
+	val := ""
+	if len(parts) >= 2 {
+		val = strings.TrimSpace(parts[1])
+	}
+
+	return key, val, nil
+}
+
+var reNotSpace = regexp.MustCompile(`\S+`)
+
+func splitColumnsBySpace(row string) ([]string, error) {
+	return reNotSpace.FindAllString(row, -1), nil
+}
+
+var reNotSpaceNorColon = regexp.MustCompile(`[^\s:]+`)
+
+func splitColumnsByColonAndSpace(row string) ([]string, error) {
+	return reNotSpaceNorColon.FindAllString(row, -1), nil
+}
+
+var reInteger = regexp.MustCompile(`^\d+$`)
+
+func isLikelyInteger(s string) bool {
+	return reInteger.MatchString(s)
+}
+
+// parseUnsignedInteger parses s as an unsigned integer. Values which do not
+// fit in int64 (e.g. addresses in the upper half) are returned as *big.Int.
+func parseUnsignedInteger(s string, base int) (interface{}, error) {
+	val, err := strconv.ParseUint(s, base, 64)
+	if err != nil {
+		return nil, err
+	}
+	if val > math.MaxInt64 {
+		return new(big.Int).SetUint64(val), nil
+	}
+	return int64(val), nil
+}
+
+// parseIntegerOrString returns s as an integer if it looks like one
+// (including negative ones such as "-1"), otherwise as it is.
+func parseIntegerOrString(s string) interface{} {
+	if isLikelyInteger(strings.TrimPrefix(s, "-")) {
+		if val, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return val
+		}
+		if val, err := parseUnsignedInteger(s, 10); err == nil {
+			return val
+		}
+	}
+	return s
+}
+
+// parseNumberOrString returns s as an integer or a float if it looks like one,
+// otherwise as it is.
+func parseNumberOrString(s string) interface{} {
+	if val, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return val
+	}
+	if val, err := strconv.ParseFloat(s, 64); err == nil {
+		return val
+	}
+	return s
+}
+
+// parseRangeList expands a list of ranges such as "0-3,8,10-11" (e.g. CPU
+// lists in sysfs) into an array of integers.
+func parseRangeList(s string) ([]interface{}, error) {
+	result := []interface{}{}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return result, nil
+	}
+
+	for _, rng := range strings.Split(s, ",") {
+		firstStr, lastStr, isRange := strings.Cut(rng, "-")
+		first, err := strconv.ParseInt(firstStr, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		last := first
+		if isRange {
+			last, err = strconv.ParseInt(lastStr, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for i := first; i <= last; i++ {
+			result = append(result, i)
+		}
+	}
+
+	return result, nil
+}
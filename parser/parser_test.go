@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMakeKeysQueryFriendly(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want interface{}
+	}{
+		{
+			name: "object",
+			v: map[string]interface{}{
+				"cpu MHz": 2400.0,
+				"TCP":     map[string]interface{}{"inuse": int64(4), "Mem Used": int64(1)},
+			},
+			want: map[string]interface{}{
+				"cpu_mhz": 2400.0,
+				"tcp":     map[string]interface{}{"inuse": int64(4), "Mem Used": int64(1)},
+			},
+		},
+		{
+			name: "array of objects",
+			v: []interface{}{
+				map[string]interface{}{"Slave Interface": "eth0", "details": map[string]interface{}{"port key": int64(9)}},
+				"not an object",
+			},
+			want: []interface{}{
+				map[string]interface{}{"slave_interface": "eth0", "details": map[string]interface{}{"port key": int64(9)}},
+				"not an object",
+			},
+		},
+		{
+			name: "scalar",
+			v:    "Foo Bar",
+			want: "Foo Bar",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := makeKeysQueryFriendly(tt.v); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+//go:build linux
+
+package parser
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+func createProcIomemParser(flat bool) treeParserFn {
+	parser := createTreeParser(parseProcIomemNode)
+	if flat {
+		return flattenTreeParser(parser)
+	}
+	return parser
+}
+
+// parseProcIomemNode parses a line of /proc/iomem or /proc/ioports such as
+// "00100000-bfffffff : System RAM".
+func parseProcIomemNode(line string) (map[string]interface{}, error) {
+	rng, name, ok := strings.Cut(line, ":")
+	if !ok {
+		return nil, errors.Errorf("unknown resource format: %s", line)
+	}
+
+	startStr, endStr, ok := strings.Cut(strings.TrimSpace(rng), "-")
+	if !ok {
+		return nil, errors.Errorf("unknown resource range format: %s", rng)
+	}
+
+	start, err := parseUnsignedInteger(startStr, 16)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseUnsignedInteger(endStr, 16)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"start": start,
+		"end":   end,
+		"name":  strings.TrimSpace(name),
+	}, nil
+}
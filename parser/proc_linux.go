@@ -1,65 +1,15 @@
 //go:build linux
 
-package cli
+package parser
 
 import (
-	"os"
-	"regexp"
+	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
 )
 
-var rePerProcess = regexp.MustCompile(`/proc/\d+/(.+)`)
-
-func (c *CLI) createInputIter(query string, args []string) (inputIter, error) {
-	if len(args) < 1 {
-		return nil, errors.New("file name argument is required")
-	}
-
-	fname := args[0]
-	f, err := os.Open(fname)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-
-	submatch := rePerProcess.FindStringSubmatch(fname)
-	if len(submatch) == 2 {
-		switch submatch[1] {
-		case "io":
-			return newProcMapIter(fname, f, createMapParser(createLineParser(splitLineByColon, parseProcPidIoValue)))
-		case "limits":
-			return newProcTableIter(fname, f, createTableParser(skipTableHeader(1), createTableRowParser(splitProcPidLimitsColumns, parseProcPidLimitsColumns)))
-		}
-	}
-
-	switch fname {
-	case "/proc/cpuinfo":
-		return newProcArrayIter(fname, f, createChunkParser(createLineParser(splitLineByColon, parseProcCpuinfoValue)))
-	case "/proc/crypto":
-		return newProcArrayIter(fname, f, createChunkParser(createLineParser(splitLineByColon, parseProcCryptoValue)))
-	case "/proc/diskstats":
-		return newProcTableIter(fname, f, createTableParser(noTableHeader, createTableRowParser(splitColumnsBySpace, parseProcDiskstatsColumns)))
-	case "/proc/meminfo":
-		return newProcMapIter(fname, f, createMapParser(createLineParser(splitLineByColon, parseProcMeminfoValue)))
-	case "/proc/modules":
-		return newProcTableIter(fname, f, createTableParser(noTableHeader, createTableRowParser(splitColumnsBySpace, parseProcModulesColumns)))
-	case "/proc/mounts":
-		return newProcTableIter(fname, f, createTableParser(noTableHeader, createTableRowParser(splitColumnsBySpace, parseProcMountsColumns)))
-	case "/proc/net/arp", "/proc/self/net/arp":
-		return newProcTableIter(fname, f, createTableParser(skipTableHeader(1), createTableRowParser(splitColumnsBySpace, parseProcNetArpColumns)))
-	case "/proc/net/dev", "/proc/self/net/dev":
-		return newProcTableIter(fname, f, createTableParser(skipTableHeader(2), createTableRowParser(splitColumnsByColonAndSpace, parseProcNetDevColumns)))
-	case "/proc/net/netlink", "/proc/self/net/netlink":
-		return newProcTableIter(fname, f, createTableParser(skipTableHeader(1), createTableRowParser(splitColumnsBySpace, parseProcNetNetlinkColumns)))
-	case "/proc/vmstat":
-		return newProcMapIter(fname, f, createMapParser(createLineParser(splitLineBySpace, parseProcVmstatValue)))
-	}
-	return nil, errors.Errorf("%s is not supported", fname)
-}
-
 func parseProcPidIoValue(key, valueStr string) (interface{}, error) {
 	return strconv.ParseInt(valueStr, 10, 64)
 }
@@ -80,12 +30,12 @@ func splitProcPidLimitsColumns(row string) ([]string, error) {
 }
 
 func parseProcPidLimitsColumns(header, columns []string) (map[string]interface{}, error) {
-	sl, err := unlimitedOrInteger(columns[1])
+	sl, err := sentinelOrInteger(columns[1], "unlimited")
 	if err != nil {
 		return nil, err
 	}
 
-	hl, err := unlimitedOrInteger(columns[2])
+	hl, err := sentinelOrInteger(columns[2], "unlimited")
 	if err != nil {
 		return nil, err
 	}
@@ -103,8 +53,10 @@ func parseProcPidLimitsColumns(header, columns []string) (map[string]interface{}
 	return result, nil
 }
 
-func unlimitedOrInteger(s string) (interface{}, error) {
-	if s == "unlimited" {
+// sentinelOrInteger returns s as an integer, or as it is if it is sentinel,
+// which stands for no limit (e.g. "unlimited" or "max").
+func sentinelOrInteger(s, sentinel string) (interface{}, error) {
+	if s == sentinel {
 		return s, nil
 	}
 
@@ -246,15 +198,19 @@ func parseProcCryptoValue(key, valueStr string) (interface{}, error) {
 	}
 }
 
+// procDiskstatsLabels are the column labels of /proc/diskstats. The columns
+// after "name" are the same as /sys/block/<dev>/stat.
+var procDiskstatsLabels = []string{"major", "minor", "name", "ios_read", "merges_read", "sectors_read", "msecs_read", "ios_write", "merges_write", "sectors_write", "msecs_write", "inflight", "io_ticks", "msecs_total", "ios_discard", "merges_discard", "sectors_discard", "msec_discard", "ios_flush", "msec_flush"}
+
 func parseProcDiskstatsColumns(_ []string, columns []string) (map[string]interface{}, error) {
 	if len(columns) < 20 {
 		return nil, errors.Errorf("unknown /proc/diskstats format: found %d columns", len(columns))
 	}
 
-	labels := []string{"major", "minor", "name", "ios_read", "merges_read", "sectors_read", "msecs_read", "ios_write", "merges_write", "sectors_write", "msecs_write", "inflight", "io_ticks", "msecs_total", "ios_discard", "merges_discard", "sectors_discard", "msec_discard", "ios_flush", "msec_flush"}
+	labels := procDiskstatsLabels
 
 	result := make(map[string]interface{})
-	for i, col := range columns {
+	for i, col := range columns[:len(labels)] {
 		if i != 2 {
 			val, err := strconv.ParseInt(col, 10, 64)
 			if err != nil {
@@ -295,38 +251,50 @@ func parseProcMeminfoValue(key, valueStr string) (interface{}, error) {
 	}, nil
 }
 
-func parseProcModulesColumns(_ []string, columns []string) (map[string]interface{}, error) {
-	if len(columns) < 6 {
-		return nil, errors.Errorf("unknown /proc/modules format. expected 6 columns but got %d columns", len(columns))
-	}
+// createProcModulesColumnsParser creates a parser of a row of /proc/modules,
+// which joins the parameters of the module in /sys/module if withParameters
+// is true.
+func createProcModulesColumnsParser(withParameters bool) tableColumnsParserFn {
+	return func(_ []string, columns []string) (map[string]interface{}, error) {
+		if len(columns) < 6 {
+			return nil, errors.Errorf("unknown /proc/modules format. expected 6 columns but got %d columns", len(columns))
+		}
 
-	var err error
-	result := make(map[string]interface{})
-	result["name"] = columns[0]
-	result["size"], err = strconv.ParseInt(columns[1], 10, 64)
-	if err != nil {
-		return nil, err
-	}
-	result["ref_count"], err = strconv.ParseInt(columns[2], 10, 64)
-	if err != nil {
-		return nil, err
-	}
+		var err error
+		result := make(map[string]interface{})
+		result["name"] = columns[0]
+		result["size"], err = strconv.ParseInt(columns[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		result["ref_count"], err = strconv.ParseInt(columns[2], 10, 64)
+		if err != nil {
+			return nil, err
+		}
 
-	var sources []interface{}
-	if strings.HasSuffix(columns[3], ",") {
-		s := strings.Split(strings.TrimSuffix(columns[3], ","), ",")
-		for _, src := range s {
-			sources = append(sources, src)
+		var sources []interface{}
+		if strings.HasSuffix(columns[3], ",") {
+			s := strings.Split(strings.TrimSuffix(columns[3], ","), ",")
+			for _, src := range s {
+				sources = append(sources, src)
+			}
+		}
+		if sources != nil {
+			result["sources"] = sources
 		}
-	}
-	if sources != nil {
-		result["sources"] = sources
-	}
 
-	result["state"] = columns[4]
-	result["base"] = columns[5]
+		result["state"] = columns[4]
+		result["base"] = columns[5]
 
-	return result, nil
+		if withParameters {
+			result["parameters"], err = parseSysModuleParameters(filepath.Join("/sys/module", columns[0], "parameters"))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return result, nil
+	}
 }
 
 func parseProcMountsColumns(_ []string, columns []string) (map[string]interface{}, error) {
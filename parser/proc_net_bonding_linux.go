@@ -0,0 +1,185 @@
+//go:build linux
+
+package parser
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// parseProcNetBonding parses /proc/net/bonding/<bond>. The global sections
+// are merged into the top-level object and the per-slave stanzas (starting
+// with "Slave Interface:") are put into "slaves".
+func parseProcNetBonding(r io.Reader) (map[string]interface{}, error) {
+	chunks, err := parseAsChunks(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	slaves := []interface{}{}
+	for _, chunk := range chunks {
+		target := result
+		if strings.HasPrefix(chunk[0], "Slave Interface:") {
+			target = make(map[string]interface{})
+			slaves = append(slaves, target)
+		}
+
+		// nested holds the object for indented lines following a line with
+		// an empty value such as "Active Aggregator Info:"
+		var nested map[string]interface{}
+		current := target
+		for _, line := range chunk {
+			key, valueStr, ok := strings.Cut(line, ":")
+			key = strings.TrimSpace(key)
+
+			// section title such as "802.3ad info"
+			if !ok {
+				current = make(map[string]interface{})
+				target[key] = current
+				nested = nil
+				continue
+			}
+
+			valueStr = strings.TrimSpace(valueStr)
+			indented := strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+			if valueStr == "" && !indented {
+				nested = make(map[string]interface{})
+				current[key] = nested
+				continue
+			}
+
+			val := parseProcNetBondingValue(valueStr)
+			if indented && nested != nil {
+				nested[key] = val
+				continue
+			}
+			nested = nil
+			current[key] = val
+		}
+	}
+	result["slaves"] = slaves
+
+	return result, nil
+}
+
+var reValueWithUnit = regexp.MustCompile(`^(\d+) ([A-Za-z]+)$`)
+
+func parseProcNetBondingValue(valueStr string) interface{} {
+	if isLikelyInteger(valueStr) {
+		if val, err := strconv.ParseInt(valueStr, 10, 64); err == nil {
+			return val
+		}
+	}
+
+	// e.g. "Speed: 10000 Mbps"
+	if submatch := reValueWithUnit.FindStringSubmatch(valueStr); len(submatch) == 3 {
+		if val, err := strconv.ParseInt(submatch[1], 10, 64); err == nil {
+			return map[string]interface{}{
+				"value": val,
+				"unit":  submatch[2],
+			}
+		}
+	}
+
+	return valueStr
+}
+
+func splitColumnsByPipe(row string) ([]string, error) {
+	var columns []string
+	for _, col := range strings.Split(row, "|") {
+		columns = append(columns, strings.TrimSpace(col))
+	}
+	return columns, nil
+}
+
+func parseProcNetVlanConfigColumns(_ []string, columns []string) (map[string]interface{}, error) {
+	if len(columns) < 3 {
+		return nil, errors.Errorf("unknown /proc/net/vlan/config format. expected 3 columns but got %d columns", len(columns))
+	}
+
+	vid, err := strconv.ParseInt(columns[1], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"device": columns[0],
+		"vid":    vid,
+		"parent": columns[2],
+	}, nil
+}
+
+var reProcNetVlanDeviceHeader = regexp.MustCompile(`^(\S+)\s+VID: (\d+)\s+REORDER_HDR: (\d+)\s+dev->priv_flags: (\S+)`)
+
+// parseProcNetVlanDevice parses /proc/net/vlan/<device>.
+func parseProcNetVlanDevice(r io.Reader) (map[string]interface{}, error) {
+	lines, err := readAllLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	statistics := make(map[string]interface{})
+	for i, line := range lines {
+		if i == 0 {
+			submatch := reProcNetVlanDeviceHeader.FindStringSubmatch(line)
+			if len(submatch) != 5 {
+				return nil, errors.Errorf("unknown /proc/net/vlan/<device> header: %s", line)
+			}
+			result["device"] = submatch[1]
+			result["vid"], _ = strconv.ParseInt(submatch[2], 10, 64)
+			result["reorder_hdr"], _ = strconv.ParseInt(submatch[3], 10, 64)
+			result["priv_flags"] = submatch[4]
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			continue
+		case strings.HasPrefix(trimmed, "Device:"):
+			result["parent"] = strings.TrimSpace(strings.TrimPrefix(trimmed, "Device:"))
+		case strings.HasPrefix(trimmed, "INGRESS priority mappings:"):
+			result["ingress_priority_mappings"] = parseProcNetVlanPriorityMappings(strings.TrimPrefix(trimmed, "INGRESS priority mappings:"))
+		case strings.HasPrefix(trimmed, "EGRESS priority mappings:"):
+			result["egress_priority_mappings"] = parseProcNetVlanPriorityMappings(strings.TrimPrefix(trimmed, "EGRESS priority mappings:"))
+		default:
+			// e.g. "total frames received            0"
+			idx := strings.LastIndex(trimmed, " ")
+			if idx < 0 {
+				return nil, errors.Errorf("unknown /proc/net/vlan/<device> line: %s", line)
+			}
+			val, err := strconv.ParseInt(trimmed[idx+1:], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			key := strings.TrimSpace(trimmed[:idx])
+			statistics[key] = val
+		}
+	}
+	result["statistics"] = statistics
+
+	return result, nil
+}
+
+// parseProcNetVlanPriorityMappings parses mappings such as "0:0  1:0  2:0".
+func parseProcNetVlanPriorityMappings(s string) map[string]interface{} {
+	result := make(map[string]interface{})
+	for _, field := range strings.Fields(s) {
+		from, to, ok := strings.Cut(field, ":")
+		if !ok {
+			continue
+		}
+		val, err := strconv.ParseInt(to, 10, 64)
+		if err != nil {
+			continue
+		}
+		result[from] = val
+	}
+	return result
+}
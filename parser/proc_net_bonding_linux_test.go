@@ -0,0 +1,159 @@
+//go:build linux
+
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testProcNetBonding = `Ethernet Channel Bonding Driver: v5.15.0-91-generic
+
+Bonding Mode: IEEE 802.3ad Dynamic link aggregation
+Transmit Hash Policy: layer2 (0)
+MII Status: up
+MII Polling Interval (ms): 100
+Up Delay (ms): 0
+Down Delay (ms): 0
+
+802.3ad info
+LACP active: on
+LACP rate: slow
+Min links: 0
+Aggregator selection policy (ad_select): stable
+System priority: 65535
+System MAC address: 52:54:00:12:34:56
+Active Aggregator Info:
+	Aggregator ID: 1
+	Number of ports: 2
+	Actor Key: 9
+	Partner Key: 1
+	Partner Mac Address: 00:00:00:00:00:00
+
+Slave Interface: eth0
+MII Status: up
+Speed: 1000 Mbps
+Duplex: full
+Link Failure Count: 0
+Permanent HW addr: 52:54:00:12:34:56
+Slave queue ID: 0
+Aggregator ID: 1
+Actor Churn State: none
+Partner Churn State: churned
+details actor lacp pdu:
+    system priority: 65535
+    system mac address: 52:54:00:12:34:56
+    port key: 9
+    port priority: 255
+    port number: 1
+    port state: 61
+details partner lacp pdu:
+    system priority: 65535
+    system mac address: 00:00:00:00:00:00
+    oper key: 1
+    port priority: 255
+    port number: 1
+    port state: 1
+
+Slave Interface: eth1
+MII Status: down
+Speed: Unknown
+Duplex: Unknown
+Link Failure Count: 3
+Permanent HW addr: 52:54:00:12:34:57
+Slave queue ID: 0
+Aggregator ID: 2
+details actor lacp pdu:
+    port key: 0
+    port number: 2
+`
+
+func TestParseProcNetBonding(t *testing.T) {
+	got, err := parseProcNetBonding(strings.NewReader(testProcNetBonding))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []digTest{
+		{[]interface{}{"Ethernet Channel Bonding Driver"}, "v5.15.0-91-generic"},
+		{[]interface{}{"Bonding Mode"}, "IEEE 802.3ad Dynamic link aggregation"},
+		{[]interface{}{"MII Polling Interval (ms)"}, int64(100)},
+		{[]interface{}{"802.3ad info", "LACP rate"}, "slow"},
+		{[]interface{}{"802.3ad info", "System MAC address"}, "52:54:00:12:34:56"},
+		{[]interface{}{"802.3ad info", "Active Aggregator Info", "Number of ports"}, int64(2)},
+		{[]interface{}{"802.3ad info", "Active Aggregator Info", "Partner Mac Address"}, "00:00:00:00:00:00"},
+		{[]interface{}{"slaves", 0, "Slave Interface"}, "eth0"},
+		{[]interface{}{"slaves", 0, "Speed"}, map[string]interface{}{"value": int64(1000), "unit": "Mbps"}},
+		{[]interface{}{"slaves", 0, "Partner Churn State"}, "churned"},
+		{[]interface{}{"slaves", 0, "details actor lacp pdu", "port key"}, int64(9)},
+		{[]interface{}{"slaves", 0, "details actor lacp pdu", "system mac address"}, "52:54:00:12:34:56"},
+		{[]interface{}{"slaves", 0, "details partner lacp pdu", "oper key"}, int64(1)},
+		{[]interface{}{"slaves", 0, "details partner lacp pdu", "port state"}, int64(1)},
+		{[]interface{}{"slaves", 1, "Slave Interface"}, "eth1"},
+		{[]interface{}{"slaves", 1, "Speed"}, "Unknown"},
+		{[]interface{}{"slaves", 1, "Link Failure Count"}, int64(3)},
+		{[]interface{}{"slaves", 1, "details actor lacp pdu"}, map[string]interface{}{"port key": int64(0), "port number": int64(2)}},
+	}
+	for _, tt := range tests {
+		if v := dig(got, tt.path...); !reflect.DeepEqual(v, tt.want) {
+			t.Errorf("%v: got %#v, want %#v", tt.path, v, tt.want)
+		}
+	}
+
+	// the slaves and the section are not merged into the top-level object
+	for _, key := range []string{"Slave Interface", "LACP rate", "port key"} {
+		if _, ok := got[key]; ok {
+			t.Errorf("unexpected top-level key %q", key)
+		}
+	}
+	if n := len(got["slaves"].([]interface{})); n != 2 {
+		t.Errorf("got %d slaves, want 2", n)
+	}
+}
+
+const testProcNetVlanDevice = `eth0.100  VID: 100	 REORDER_HDR: 1  dev->priv_flags: 1021
+         total frames received           10
+          total bytes received          840
+      Broadcast/Multicast Rcvd            2
+
+      total frames transmitted           12
+       total bytes transmitted          936
+Device: eth0
+INGRESS priority mappings: 0:0  1:0  2:0  3:0  4:0  5:0  6:0 7:0
+ EGRESS priority mappings: 5:3 
+`
+
+func TestParseProcNetVlanDevice(t *testing.T) {
+	got, err := parseProcNetVlanDevice(strings.NewReader(testProcNetVlanDevice))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"device":      "eth0.100",
+		"vid":         int64(100),
+		"reorder_hdr": int64(1),
+		"priv_flags":  "1021",
+		"parent":      "eth0",
+		"statistics": map[string]interface{}{
+			"total frames received":    int64(10),
+			"total bytes received":     int64(840),
+			"Broadcast/Multicast Rcvd": int64(2),
+			"total frames transmitted": int64(12),
+			"total bytes transmitted":  int64(936),
+		},
+		"ingress_priority_mappings": map[string]interface{}{
+			"0": int64(0), "1": int64(0), "2": int64(0), "3": int64(0),
+			"4": int64(0), "5": int64(0), "6": int64(0), "7": int64(0),
+		},
+		"egress_priority_mappings": map[string]interface{}{"5": int64(3)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+
+	if _, err := parseProcNetVlanDevice(strings.NewReader("eth0.100 something else\n")); err == nil {
+		t.Error("expected an error for an unknown header")
+	}
+}
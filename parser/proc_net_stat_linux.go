@@ -0,0 +1,98 @@
+//go:build linux
+
+package parser
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// the columns which are not listed here are always zero
+var procNetSoftnetStatLabels = map[int]string{
+	0:  "processed",
+	1:  "dropped",
+	2:  "time_squeeze",
+	8:  "cpu_collision",
+	9:  "received_rps",
+	10: "flow_limit_count",
+	11: "backlog_len",
+	12: "cpu",
+	13: "input_qlen",
+	14: "process_qlen",
+}
+
+func parseProcNetSoftnetStat(r io.Reader) ([]interface{}, error) {
+	rows, err := createTableParser(noTableHeader, createTableRowParser(splitColumnsBySpace, parseProcNetSoftnetStatColumns))(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// older kernels do not print the CPU index, so assume that the rows are
+	// ordered by CPU
+	for i, row := range rows {
+		m := row.(map[string]interface{})
+		if _, ok := m["cpu"]; !ok {
+			m["cpu"] = int64(i)
+		}
+	}
+
+	return rows, nil
+}
+
+func parseProcNetSoftnetStatColumns(_ []string, columns []string) (map[string]interface{}, error) {
+	if len(columns) < 11 {
+		return nil, errors.Errorf("unknown /proc/net/softnet_stat format. expected at least 11 columns but got %d columns", len(columns))
+	}
+
+	result := make(map[string]interface{})
+	for i, col := range columns {
+		label, ok := procNetSoftnetStatLabels[i]
+		if !ok {
+			continue
+		}
+		val, err := strconv.ParseInt(col, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		result[label] = val
+	}
+
+	return result, nil
+}
+
+// parseProcNetSockstatValue parses the values of /proc/net/sockstat such as
+// "inuse 4 orphan 0 tw 0 alloc 4 mem 0".
+func parseProcNetSockstatValue(key, valueStr string) (interface{}, error) {
+	columns, _ := splitColumnsBySpace(valueStr)
+	if len(columns)%2 != 0 {
+		return nil, errors.Errorf("unknown /proc/net/sockstat format: %s: %s", key, valueStr)
+	}
+
+	result := make(map[string]interface{})
+	for i := 0; i < len(columns); i += 2 {
+		val, err := strconv.ParseInt(columns[i+1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		result[columns[i]] = val
+	}
+
+	return result, nil
+}
+
+func parseProcNetProtocolsValue(key, valueStr string) (interface{}, error) {
+	switch valueStr {
+	case "yes", "y":
+		return true, nil
+	case "no", "n":
+		return false, nil
+	}
+
+	if val, err := strconv.ParseInt(valueStr, 10, 64); err == nil {
+		return val, nil
+	}
+
+	return valueStr, nil
+}
@@ -0,0 +1,255 @@
+//go:build linux
+
+package parser
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// parseProcPidWchan parses /proc/[pid]/wchan, which holds the symbol the
+// process is waiting in, or "0" if it is not waiting.
+func parseProcPidWchan(r io.Reader) (map[string]interface{}, error) {
+	s, err := readTrimmed(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var wchan interface{}
+	if s != "0" && s != "" {
+		wchan = s
+	}
+	return map[string]interface{}{
+		"wchan": wchan,
+	}, nil
+}
+
+var reProcPidStackFrame = regexp.MustCompile(`^\[<([0-9a-f]+)>\] ([^+\s]+)(?:\+0x([0-9a-f]+)/0x([0-9a-f]+))?(?: \[(\S+)\])?$`)
+
+// parseProcPidStack parses /proc/[pid]/stack into frames such as
+// "[<0>] do_wait+0x1c4/0x2a0 [module]".
+func parseProcPidStack(r io.Reader) ([]interface{}, error) {
+	lines, err := readAllLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []interface{}{}
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		submatch := reProcPidStackFrame.FindStringSubmatch(line)
+		if len(submatch) != 6 {
+			return nil, errors.Errorf("unknown /proc/[pid]/stack format: %s", line)
+		}
+
+		frame := map[string]interface{}{
+			"symbol": submatch[2],
+		}
+		frame["address"], err = parseUnsignedInteger(submatch[1], 16)
+		if err != nil {
+			return nil, err
+		}
+		if submatch[3] != "" {
+			frame["offset"], err = parseUnsignedInteger(submatch[3], 16)
+			if err != nil {
+				return nil, err
+			}
+			frame["size"], err = parseUnsignedInteger(submatch[4], 16)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if submatch[5] != "" {
+			frame["module"] = submatch[5]
+		}
+		result = append(result, frame)
+	}
+
+	return result, nil
+}
+
+//go:generate go run gen_syscall_names.go
+
+// parseProcPidSyscall parses /proc/[pid]/syscall, which is either "running",
+// "-1 <sp> <pc>" when the process is blocked outside of a system call, or
+// "<nr> <arg1> ... <arg6> <sp> <pc>". The number is named by syscallNames of
+// the architecture sq is built for, so the names of 32-bit compat processes
+// (e.g. i386 on amd64, or arm on arm64) are wrong, as their numbers differ.
+// name is left out if the number is unknown.
+func parseProcPidSyscall(r io.Reader) (map[string]interface{}, error) {
+	s, err := readTrimmed(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if s == "running" {
+		return map[string]interface{}{
+			"running": true,
+		}, nil
+	}
+
+	columns, _ := splitColumnsBySpace(s)
+	if len(columns) != 3 && len(columns) != 9 {
+		return nil, errors.Errorf("unknown /proc/[pid]/syscall format: %s", s)
+	}
+
+	nr, err := strconv.ParseInt(columns[0], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{
+		"running": false,
+		"number":  nr,
+	}
+	if name, ok := syscallNames[nr]; ok {
+		result["name"] = name
+	}
+
+	var values []interface{}
+	for _, col := range columns[1:] {
+		val, err := parseUnsignedInteger(strings.TrimPrefix(col, "0x"), 16)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, val)
+	}
+	if len(values) == 8 {
+		result["args"] = values[:6]
+		values = values[6:]
+	}
+	result["sp"] = values[0]
+	result["pc"] = values[1]
+
+	return result, nil
+}
+
+var personas = map[int64]string{
+	0x00: "PER_LINUX",
+	0x01: "PER_SVR4",
+	0x02: "PER_SVR3",
+	0x03: "PER_SCOSVR3",
+	0x04: "PER_WYSEV386",
+	0x05: "PER_ISCR4",
+	0x06: "PER_BSD",
+	0x07: "PER_XENIX",
+	0x08: "PER_LINUX32",
+	0x09: "PER_IRIX32",
+	0x0a: "PER_IRIXN32",
+	0x0b: "PER_IRIX64",
+	0x0c: "PER_RISCOS",
+	0x0d: "PER_SOLARIS",
+	0x0e: "PER_UW7",
+	0x0f: "PER_OSF4",
+	0x10: "PER_HPUX",
+}
+
+var personalityFlags = []struct {
+	name  string
+	value int64
+}{
+	{"UNAME26", 0x0020000},
+	{"ADDR_NO_RANDOMIZE", 0x0040000},
+	{"FDPIC_FUNCPTRS", 0x0080000},
+	{"MMAP_PAGE_ZERO", 0x0100000},
+	{"ADDR_COMPAT_LAYOUT", 0x0200000},
+	{"READ_IMPLIES_EXEC", 0x0400000},
+	{"ADDR_LIMIT_32BIT", 0x0800000},
+	{"SHORT_INODE", 0x1000000},
+	{"WHOLE_SECONDS", 0x2000000},
+	{"STICKY_TIMEOUTS", 0x4000000},
+	{"ADDR_LIMIT_3GB", 0x8000000},
+}
+
+// parseProcPidPersonality decodes /proc/[pid]/personality (e.g. "00400000")
+// into the execution domain and the flag names (see personality(2)).
+func parseProcPidPersonality(r io.Reader) (map[string]interface{}, error) {
+	s, err := readTrimmed(r)
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := strconv.ParseInt(s, 16, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	persona, ok := personas[val&0xff]
+	if !ok {
+		persona = "unknown"
+	}
+
+	flags := []interface{}{}
+	for _, flag := range personalityFlags {
+		if val&flag.value != 0 {
+			flags = append(flags, flag.name)
+		}
+	}
+
+	return map[string]interface{}{
+		"value":   val,
+		"persona": persona,
+		"flags":   flags,
+	}, nil
+}
+
+// parseProcPidHangReport combines what is needed to see why a process hangs:
+// its state from status, wchan, stack, syscall and personality. The files
+// which cannot be read (e.g. stack requires root) are reported as null.
+func parseProcPidHangReport(dir string) (interface{}, error) {
+	f, err := os.Open(filepath.Join(dir, "status"))
+	if err != nil {
+		return nil, err
+	}
+	status, err := createMapParser(createLineParser(splitLineByFirstColon, parseProcPidStatusValue))(f)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"pid":   status["Pid"],
+		"name":  status["Name"],
+		"state": status["State"],
+	}
+
+	readFile := func(name string, parser func(io.Reader) (interface{}, error)) interface{} {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			return nil
+		}
+		defer f.Close()
+		val, err := parser(f)
+		if err != nil {
+			return nil
+		}
+		return val
+	}
+
+	result["wchan"] = readFile("wchan", func(r io.Reader) (interface{}, error) {
+		m, err := parseProcPidWchan(r)
+		if err != nil {
+			return nil, err
+		}
+		return m["wchan"], nil
+	})
+	result["stack"] = readFile("stack", func(r io.Reader) (interface{}, error) {
+		return parseProcPidStack(r)
+	})
+	result["syscall"] = readFile("syscall", func(r io.Reader) (interface{}, error) {
+		return parseProcPidSyscall(r)
+	})
+	result["personality"] = readFile("personality", func(r io.Reader) (interface{}, error) {
+		return parseProcPidPersonality(r)
+	})
+
+	return result, nil
+}
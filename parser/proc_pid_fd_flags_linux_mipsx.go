@@ -0,0 +1,24 @@
+//go:build linux && (mips || mipsle || mips64 || mips64le)
+
+package parser
+
+// open flags in arch/mips/include/uapi/asm/fcntl.h
+const (
+	oAppend    = 0x0008
+	oDsync     = 0x0010
+	oNonblock  = 0x0080
+	oCreat     = 0x0100
+	oTrunc     = 0x0200
+	oExcl      = 0x0400
+	oNoctty    = 0x0800
+	oAsync     = 0x1000
+	oLargefile = 0x2000
+	oSync      = 0x4000
+	oDirect    = 0x8000
+	oDirectory = 0200000
+	oNofollow  = 0400000
+	oNoatime   = 01000000
+	oCloexec   = 02000000
+	oPath      = 010000000
+	oTmpfile   = 020000000
+)
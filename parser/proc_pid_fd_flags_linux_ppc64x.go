@@ -0,0 +1,24 @@
+//go:build linux && (ppc64 || ppc64le)
+
+package parser
+
+// open flags in arch/powerpc/include/uapi/asm/fcntl.h
+const (
+	oCreat     = 0100
+	oExcl      = 0200
+	oNoctty    = 0400
+	oTrunc     = 01000
+	oAppend    = 02000
+	oNonblock  = 04000
+	oDsync     = 010000
+	oAsync     = 020000
+	oDirectory = 040000
+	oNofollow  = 0100000
+	oLargefile = 0200000
+	oDirect    = 0400000
+	oSync      = 04000000
+	oNoatime   = 01000000
+	oCloexec   = 02000000
+	oPath      = 010000000
+	oTmpfile   = 020000000
+)
@@ -0,0 +1,33 @@
+//go:build linux && !arm && !arm64 && !ppc64 && !ppc64le && !mips && !mipsle && !mips64 && !mips64le
+
+package parser
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestDecodeOpenFlags(t *testing.T) {
+	tests := []struct {
+		flags string
+		want  []interface{}
+	}{
+		{"0", []interface{}{"O_RDONLY"}},
+		{"0100002", []interface{}{"O_RDWR", "O_LARGEFILE"}},
+		{"02004002", []interface{}{"O_RDWR", "O_NONBLOCK", "O_CLOEXEC"}},
+		{"02102", []interface{}{"O_RDWR", "O_CREAT", "O_APPEND"}},
+		{"02200001", []interface{}{"O_WRONLY", "O_DIRECTORY", "O_CLOEXEC"}},
+		{"012100000", []interface{}{"O_RDONLY", "O_LARGEFILE", "O_CLOEXEC", "O_PATH"}},
+	}
+
+	for _, tt := range tests {
+		flags, err := strconv.ParseInt(tt.flags, 8, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := decodeOpenFlags(flags); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.flags, got, tt.want)
+		}
+	}
+}
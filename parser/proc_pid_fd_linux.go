@@ -0,0 +1,278 @@
+//go:build linux
+
+package parser
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// open flags as seen by the kernel (i.e. include/uapi/asm*/fcntl.h), which
+// are not always the same as the ones in the syscall package (e.g.
+// O_LARGEFILE is 0 on 64-bit architectures there). Their values are in
+// proc_pid_fd_flags_linux*.go per architecture.
+var openFlags = []struct {
+	name  string
+	value int64
+}{
+	{"O_CREAT", oCreat},
+	{"O_EXCL", oExcl},
+	{"O_NOCTTY", oNoctty},
+	{"O_TRUNC", oTrunc},
+	{"O_APPEND", oAppend},
+	{"O_NONBLOCK", oNonblock},
+	{"O_DSYNC", oDsync},
+	{"O_ASYNC", oAsync},
+	{"O_DIRECT", oDirect},
+	{"O_LARGEFILE", oLargefile},
+	{"O_DIRECTORY", oDirectory},
+	{"O_NOFOLLOW", oNofollow},
+	{"O_NOATIME", oNoatime},
+	{"O_CLOEXEC", oCloexec},
+	{"O_SYNC", oSync},
+	{"O_PATH", oPath},
+	{"O_TMPFILE", oTmpfile},
+}
+
+func decodeOpenFlags(flags int64) []interface{} {
+	var result []interface{}
+	switch flags & 03 {
+	case 0:
+		result = append(result, "O_RDONLY")
+	case 01:
+		result = append(result, "O_WRONLY")
+	case 02:
+		result = append(result, "O_RDWR")
+	}
+
+	for _, flag := range openFlags {
+		if flags&flag.value != 0 {
+			result = append(result, flag.name)
+		}
+	}
+
+	return result
+}
+
+var reFdTarget = regexp.MustCompile(`^(socket|pipe|anon_inode):\[?([^\]]*)\]?$`)
+
+// parseProcPidFdDir lists the open files of a process from /proc/[pid]/fd and
+// merges each of them with its /proc/[pid]/fdinfo/[fd].
+func parseProcPidFdDir(dir string) (interface{}, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var fds []int64
+	for _, entry := range entries {
+		fd, err := strconv.ParseInt(entry.Name(), 10, 64)
+		if err != nil {
+			continue
+		}
+		fds = append(fds, fd)
+	}
+	sort.Slice(fds, func(i, j int) bool { return fds[i] < fds[j] })
+
+	fdinfoDir := filepath.Join(filepath.Dir(filepath.Clean(dir)), "fdinfo")
+	result := []interface{}{}
+	for _, fd := range fds {
+		name := strconv.FormatInt(fd, 10)
+		target, err := os.Readlink(filepath.Join(dir, name))
+		if err != nil {
+			// the file has been closed in the meantime
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		entry := parseFdTarget(target)
+		entry["fd"] = fd
+
+		if f, err := os.Open(filepath.Join(fdinfoDir, name)); err == nil {
+			info, err := parseProcPidFdinfo(f)
+			f.Close()
+			if err != nil {
+				return nil, err
+			}
+			for key, val := range info {
+				entry[key] = val
+			}
+		}
+
+		result = append(result, entry)
+	}
+
+	return result, nil
+}
+
+// parseFdTarget parses the link target of /proc/[pid]/fd/[fd] such as
+// "socket:[12345]", "anon_inode:[eventfd]" or "/path/to/file (deleted)".
+func parseFdTarget(target string) map[string]interface{} {
+	result := map[string]interface{}{
+		"target": target,
+	}
+
+	if submatch := reFdTarget.FindStringSubmatch(target); len(submatch) == 3 {
+		result["type"] = submatch[1]
+		if submatch[1] == "anon_inode" {
+			result["name"] = submatch[2]
+		} else if inode, err := strconv.ParseInt(submatch[2], 10, 64); err == nil {
+			result["inode"] = inode
+		}
+		return result
+	}
+
+	if strings.HasSuffix(target, " (deleted)") {
+		result["type"] = "deleted"
+		result["path"] = strings.TrimSuffix(target, " (deleted)")
+		return result
+	}
+
+	result["type"] = "file"
+	result["path"] = target
+	return result
+}
+
+// keys of fdinfo (e.g. signalfd, epoll and inotify) printed in hex, other
+// than the ones of the common lines
+var fdinfoHexKeys = map[string]bool{
+	"data":          true,
+	"event-flags":   true,
+	"events":        true,
+	"fhandle-bytes": true,
+	"fhandle-type":  true,
+	"flags":         true, // of fanotify
+	"ignored_mask":  true,
+	"ino":           true,
+	"mask":          true,
+	"mflags":        true,
+	"sdev":          true,
+	"sigmask":       true,
+	"wd":            true,
+}
+
+// keys of fdinfo printed in decimal, other than the ones of the common lines
+var fdinfoDecimalKeys = map[string]bool{
+	"clockid":   true,
+	"pos":       true,
+	"tfd":       true,
+	"ticks":     true,
+	"tty-index": true,
+}
+
+// parseFdinfoValue parses a value of fdinfo by the base of its key. Values of
+// unknown keys are kept as strings, as they may be in any base.
+func parseFdinfoValue(key, valueStr string) interface{} {
+	base := 0
+	switch {
+	case fdinfoHexKeys[key]:
+		base = 16
+	case fdinfoDecimalKeys[key]:
+		base = 10
+	}
+	if base != 0 {
+		if val, err := parseUnsignedInteger(valueStr, base); err == nil {
+			return val
+		}
+	}
+	return valueStr
+}
+
+// parseProcPidFdinfo parses /proc/[pid]/fdinfo/[fd]. The extra lines of
+// eventfd, epoll and inotify are put into "eventfd", "epoll" and "inotify".
+func parseProcPidFdinfo(r io.Reader) (map[string]interface{}, error) {
+	lines, err := readAllLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		// e.g. "tfd:        4 events:       1f data:                4  pos:0 ino:1de1 sdev:f"
+		if strings.HasPrefix(line, "tfd:") {
+			epoll, _ := result["epoll"].([]interface{})
+			result["epoll"] = append(epoll, parseFdinfoPairs(line))
+			continue
+		}
+
+		// e.g. "inotify wd:1 ino:1de1 sdev:f mask:fc6 ignored_mask:0 ..."
+		if name, pairs, ok := strings.Cut(line, " "); ok && (name == "inotify" || name == "fanotify") {
+			entries, _ := result[name].([]interface{})
+			result[name] = append(entries, parseFdinfoPairs(pairs))
+			continue
+		}
+
+		key, valueStr, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, errors.Errorf("unknown fdinfo line: %s", line)
+		}
+		valueStr = strings.TrimSpace(valueStr)
+
+		switch key {
+		case "pos", "mnt_id", "ino":
+			result[key], err = strconv.ParseInt(valueStr, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+		case "flags":
+			flags, err := strconv.ParseInt(valueStr, 8, 64)
+			if err != nil {
+				return nil, err
+			}
+			result["flags"] = decodeOpenFlags(flags)
+			result["flags_octal"] = valueStr
+		case "eventfd-count", "eventfd-id", "eventfd-semaphore":
+			base := 10
+			if key == "eventfd-count" {
+				base = 16
+			}
+			val, err := strconv.ParseInt(valueStr, base, 64)
+			if err != nil {
+				return nil, err
+			}
+			eventfd, _ := result["eventfd"].(map[string]interface{})
+			if eventfd == nil {
+				eventfd = make(map[string]interface{})
+				result["eventfd"] = eventfd
+			}
+			eventfd[strings.TrimPrefix(key, "eventfd-")] = val
+		default:
+			result[key] = parseFdinfoValue(key, valueStr)
+		}
+	}
+
+	return result, nil
+}
+
+// parseFdinfoPairs parses "key:value" pairs where the value may be separated
+// from the key by spaces (e.g. "tfd:        4 events:       1f").
+func parseFdinfoPairs(s string) map[string]interface{} {
+	result := make(map[string]interface{})
+	fields := strings.Fields(s)
+	for i := 0; i < len(fields); i++ {
+		key, valueStr, ok := strings.Cut(fields[i], ":")
+		if !ok {
+			continue
+		}
+		if valueStr == "" && i+1 < len(fields) {
+			i++
+			valueStr = fields[i]
+		}
+
+		result[key] = parseFdinfoValue(key, valueStr)
+	}
+	return result
+}
@@ -0,0 +1,102 @@
+//go:build linux
+
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseProcPidFdinfo(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]interface{}
+	}{
+		{
+			name:  "file",
+			input: "pos:\t4096\nflags:\t02\nmnt_id:\t29\nino:\t1057\n",
+			want: map[string]interface{}{
+				"pos":         int64(4096),
+				"mnt_id":      int64(29),
+				"ino":         int64(1057),
+				"flags_octal": "02",
+			},
+		},
+		{
+			name:  "eventfd",
+			input: "pos:\t0\nflags:\t02\nmnt_id:\t15\nino:\t1057\neventfd-count:               1a\neventfd-id: 5\neventfd-semaphore: 1\n",
+			want: map[string]interface{}{
+				"pos":         int64(0),
+				"mnt_id":      int64(15),
+				"ino":         int64(1057),
+				"flags_octal": "02",
+				"eventfd": map[string]interface{}{
+					"count":     int64(26),
+					"id":        int64(5),
+					"semaphore": int64(1),
+				},
+			},
+		},
+		{
+			name:  "epoll",
+			input: "pos:\t0\nflags:\t02\nmnt_id:\t15\nino:\t1057\ntfd:        5 events:       19 data:                5  pos:0 ino:1de1 sdev:f\n",
+			want: map[string]interface{}{
+				"pos":         int64(0),
+				"mnt_id":      int64(15),
+				"ino":         int64(1057),
+				"flags_octal": "02",
+				"epoll": []interface{}{
+					map[string]interface{}{
+						"tfd":    int64(5),
+						"events": int64(25),
+						"data":   int64(5),
+						"pos":    int64(0),
+						"ino":    int64(7649),
+						"sdev":   int64(15),
+					},
+				},
+			},
+		},
+		{
+			name:  "inotify",
+			input: "pos:\t0\nflags:\t02\nmnt_id:\t15\nino:\t1057\ninotify wd:1 ino:1de1 sdev:f mask:fc6 ignored_mask:0 fhandle-bytes:8 fhandle-type:1 f_handle:e11d000033dc2a7f\n",
+			want: map[string]interface{}{
+				"pos":         int64(0),
+				"mnt_id":      int64(15),
+				"ino":         int64(1057),
+				"flags_octal": "02",
+				"inotify": []interface{}{
+					map[string]interface{}{
+						"wd":            int64(1),
+						"ino":           int64(7649),
+						"sdev":          int64(15),
+						"mask":          int64(4038),
+						"ignored_mask":  int64(0),
+						"fhandle-bytes": int64(8),
+						"fhandle-type":  int64(1),
+						"f_handle":      "e11d000033dc2a7f",
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseProcPidFdinfo(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the names of flags differ between architectures
+			if _, ok := got["flags"]; !ok {
+				t.Error("flags is missing")
+			}
+			delete(got, "flags")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
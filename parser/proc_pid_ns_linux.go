@@ -0,0 +1,135 @@
+//go:build linux
+
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var reNsTarget = regexp.MustCompile(`^(\w+):\[(\d+)\]$`)
+
+// parseProcPidNsDir reads /proc/[pid]/ns into an object which maps the
+// namespace types to their inode numbers.
+func parseProcPidNsDir(dir string) (interface{}, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	for _, entry := range entries {
+		target, err := os.Readlink(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		submatch := reNsTarget.FindStringSubmatch(target)
+		if len(submatch) != 3 {
+			return nil, errors.Errorf("unknown namespace link target: %s", target)
+		}
+		result[entry.Name()], err = strconv.ParseInt(submatch[2], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// parseAllProcNs groups all processes by the namespaces they belong to. Each
+// namespace comes with its member PIDs and the command line of the first
+// member as a representative.
+func parseAllProcNs(_ string) (interface{}, error) {
+	pids, err := listPids()
+	if err != nil {
+		return nil, err
+	}
+
+	type nsKey struct {
+		typ   string
+		inode int64
+	}
+	members := make(map[nsKey][]interface{})
+	commands := make(map[nsKey]string)
+	for _, pid := range pids {
+		ns, err := parseProcPidNsDir(filepath.Join("/proc", strconv.FormatInt(pid, 10), "ns"))
+		if err != nil {
+			// the process has exited or is not accessible
+			continue
+		}
+
+		for typ, inode := range ns.(map[string]interface{}) {
+			if strings.HasSuffix(typ, "_for_children") {
+				continue
+			}
+			key := nsKey{typ: typ, inode: inode.(int64)}
+			if _, ok := commands[key]; !ok {
+				commands[key] = readProcPidCommand(pid)
+			}
+			members[key] = append(members[key], pid)
+		}
+	}
+
+	keys := make([]nsKey, 0, len(members))
+	for key := range members {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].typ != keys[j].typ {
+			return keys[i].typ < keys[j].typ
+		}
+		return keys[i].inode < keys[j].inode
+	})
+
+	result := []interface{}{}
+	for _, key := range keys {
+		result = append(result, map[string]interface{}{
+			"type":    key.typ,
+			"inode":   key.inode,
+			"pids":    members[key],
+			"command": commands[key],
+		})
+	}
+
+	return result, nil
+}
+
+// listPids returns the PIDs of all processes in ascending order.
+func listPids() ([]int64, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	var pids []int64
+	for _, entry := range entries {
+		pid, err := strconv.ParseInt(entry.Name(), 10, 64)
+		if err != nil {
+			continue
+		}
+		pids = append(pids, pid)
+	}
+	sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+
+	return pids, nil
+}
+
+// readProcPidCommand returns the command line of a process, or its name in
+// brackets for kernel threads which have no command line.
+func readProcPidCommand(pid int64) string {
+	dir := filepath.Join("/proc", strconv.FormatInt(pid, 10))
+	if b, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil && len(b) > 0 {
+		return strings.TrimSpace(strings.ReplaceAll(string(b), "\x00", " "))
+	}
+	if b, err := os.ReadFile(filepath.Join(dir, "comm")); err == nil {
+		return "[" + strings.TrimSpace(string(b)) + "]"
+	}
+	return ""
+}
@@ -0,0 +1,281 @@
+//go:build linux
+
+package parser
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// capability names by bit number (see capabilities(7))
+var capabilityNames = []string{
+	"CAP_CHOWN",
+	"CAP_DAC_OVERRIDE",
+	"CAP_DAC_READ_SEARCH",
+	"CAP_FOWNER",
+	"CAP_FSETID",
+	"CAP_KILL",
+	"CAP_SETGID",
+	"CAP_SETUID",
+	"CAP_SETPCAP",
+	"CAP_LINUX_IMMUTABLE",
+	"CAP_NET_BIND_SERVICE",
+	"CAP_NET_BROADCAST",
+	"CAP_NET_ADMIN",
+	"CAP_NET_RAW",
+	"CAP_IPC_LOCK",
+	"CAP_IPC_OWNER",
+	"CAP_SYS_MODULE",
+	"CAP_SYS_RAWIO",
+	"CAP_SYS_CHROOT",
+	"CAP_SYS_PTRACE",
+	"CAP_SYS_PACCT",
+	"CAP_SYS_ADMIN",
+	"CAP_SYS_BOOT",
+	"CAP_SYS_NICE",
+	"CAP_SYS_RESOURCE",
+	"CAP_SYS_TIME",
+	"CAP_SYS_TTY_CONFIG",
+	"CAP_MKNOD",
+	"CAP_LEASE",
+	"CAP_AUDIT_WRITE",
+	"CAP_AUDIT_CONTROL",
+	"CAP_SETFCAP",
+	"CAP_MAC_OVERRIDE",
+	"CAP_MAC_ADMIN",
+	"CAP_SYSLOG",
+	"CAP_WAKE_ALARM",
+	"CAP_BLOCK_SUSPEND",
+	"CAP_AUDIT_READ",
+	"CAP_PERFMON",
+	"CAP_BPF",
+	"CAP_CHECKPOINT_RESTORE",
+}
+
+// decodeCapabilities decodes a capability set in hex (e.g. CapEff in
+// /proc/[pid]/status) into the capability names.
+func decodeCapabilities(hex string) ([]interface{}, error) {
+	val, err := strconv.ParseUint(hex, 16, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []interface{}{}
+	for bit := 0; bit < 64; bit++ {
+		if val&(1<<bit) == 0 {
+			continue
+		}
+		if bit < len(capabilityNames) {
+			result = append(result, capabilityNames[bit])
+		} else {
+			result = append(result, "CAP_"+strconv.Itoa(bit))
+		}
+	}
+	return result, nil
+}
+
+var seccompModes = map[int64]string{
+	0: "disabled",
+	1: "strict",
+	2: "filter",
+}
+
+// unsetOrInteger reports the "unset" value of loginuid and sessionid, i.e.
+// (uint32)-1, as "unset".
+func unsetOrInteger(s string) (interface{}, error) {
+	if s == "4294967295" {
+		return "unset", nil
+	}
+
+	return strconv.ParseInt(s, 10, 64)
+}
+
+func parseProcPidLoginuid(r io.Reader) (map[string]interface{}, error) {
+	return parseProcPidAuditID("loginuid", r)
+}
+
+func parseProcPidSessionid(r io.Reader) (map[string]interface{}, error) {
+	return parseProcPidAuditID("sessionid", r)
+}
+
+func parseProcPidAuditID(key string, r io.Reader) (map[string]interface{}, error) {
+	s, err := readTrimmed(r)
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := unsetOrInteger(s)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		key: val,
+	}, nil
+}
+
+// createProcPidAttrCurrentParser creates a parser of the LSM label of
+// /proc/[pid]/attr/current such as "system_u:system_r:init_t:s0" (SELinux) or
+// "/usr/bin/foo (enforce)" (AppArmor). The LSM is told by the active LSMs of
+// the local host only if hostLSM is true, i.e. the label is read from the
+// local /proc rather than from stdin or another machine.
+func createProcPidAttrCurrentParser(hostLSM bool) func(io.Reader) (map[string]interface{}, error) {
+	return func(r io.Reader) (map[string]interface{}, error) {
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		label := strings.TrimRight(string(b), "\x00\n")
+
+		result := map[string]interface{}{
+			"label": label,
+			"lsm":   detectLSM(label, hostLSM),
+		}
+
+		switch result["lsm"] {
+		case "selinux":
+			parts := strings.SplitN(label, ":", 4)
+			if len(parts) < 3 {
+				break
+			}
+			result["user"] = parts[0]
+			result["role"] = parts[1]
+			result["type"] = parts[2]
+			if len(parts) == 4 {
+				result["level"] = parts[3]
+			}
+		case "apparmor":
+			profile := label
+			if i := strings.LastIndex(label, " ("); i >= 0 && strings.HasSuffix(label, ")") {
+				profile = label[:i]
+				result["mode"] = label[i+2 : len(label)-1]
+			}
+			result["profile"] = profile
+		}
+
+		return result, nil
+	}
+}
+
+// detectLSM tells which LSM the label comes from, by the active LSMs of the
+// local host if hostLSM is true and they can be read, or by the format of the
+// label otherwise.
+func detectLSM(label string, hostLSM bool) string {
+	if b, err := os.ReadFile("/sys/kernel/security/lsm"); hostLSM && err == nil {
+		for _, lsm := range strings.Split(strings.TrimSpace(string(b)), ",") {
+			switch lsm {
+			case "selinux", "apparmor", "smack":
+				return lsm
+			}
+		}
+	}
+
+	switch {
+	case strings.Count(label, ":") >= 2:
+		return "selinux"
+	case label == "unconfined", strings.HasSuffix(label, ")") && strings.Contains(label, " ("):
+		return "apparmor"
+	}
+	return "unknown"
+}
+
+// parseProcPidSecurity builds the security context of a process from
+// /proc/[pid]/attr/current, loginuid, sessionid and status.
+func parseProcPidSecurity(attrDir string) (interface{}, error) {
+	dir := filepath.Dir(filepath.Clean(attrDir))
+
+	f, err := os.Open(filepath.Join(dir, "status"))
+	if err != nil {
+		return nil, err
+	}
+	status, err := createMapParser(createLineParser(splitLineByFirstColon, parseProcPidStatusValue))(f)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{
+		"pid":  status["Pid"],
+		"name": status["Name"],
+		"uid":  status["Uid"],
+		"gid":  status["Gid"],
+	}
+
+	if f, err := os.Open(filepath.Join(dir, "attr", "current")); err == nil {
+		lsm, err := createProcPidAttrCurrentParser(true)(f)
+		f.Close()
+		if err == nil {
+			result["lsm"] = lsm
+		}
+	}
+	if _, ok := result["lsm"]; !ok {
+		result["lsm"] = nil
+	}
+
+	for key, parser := range map[string]func(io.Reader) (map[string]interface{}, error){
+		"loginuid":  parseProcPidLoginuid,
+		"sessionid": parseProcPidSessionid,
+	} {
+		result[key] = nil
+		f, err := os.Open(filepath.Join(dir, key))
+		if err != nil {
+			continue
+		}
+		m, err := parser(f)
+		f.Close()
+		if err != nil {
+			continue
+		}
+		result[key] = m[key]
+	}
+
+	if mode, ok := status["Seccomp"].(int64); ok {
+		result["seccomp"] = seccompModes[mode]
+	}
+	result["seccomp_filters"] = status["Seccomp_filters"]
+	if nnp, ok := status["NoNewPrivs"].(int64); ok {
+		result["no_new_privs"] = nnp != 0
+	}
+
+	capabilities := make(map[string]interface{})
+	for key, label := range map[string]string{
+		"CapInh": "inheritable",
+		"CapPrm": "permitted",
+		"CapEff": "effective",
+		"CapBnd": "bounding",
+		"CapAmb": "ambient",
+	} {
+		hex, ok := status[key].(string)
+		if !ok {
+			continue
+		}
+		capabilities[label], err = decodeCapabilities(hex)
+		if err != nil {
+			return nil, err
+		}
+	}
+	result["capabilities"] = capabilities
+
+	return result, nil
+}
+
+// parseAllProcSecurity builds the security contexts of all processes.
+func parseAllProcSecurity(_ string) (interface{}, error) {
+	pids, err := listPids()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []interface{}{}
+	for _, pid := range pids {
+		security, err := parseProcPidSecurity(filepath.Join("/proc", strconv.FormatInt(pid, 10), "attr"))
+		if err != nil {
+			// the process has exited in the meantime
+			continue
+		}
+		result = append(result, security)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,60 @@
+//go:build linux
+
+package parser
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// labels of the fields following comm in /proc/[pid]/stat (see proc(5))
+var procPidStatLabels = []string{"state", "ppid", "pgrp", "session", "tty_nr", "tpgid", "flags", "minflt", "cminflt", "majflt", "cmajflt", "utime", "stime", "cutime", "cstime", "priority", "nice", "num_threads", "itrealvalue", "starttime", "vsize", "rss", "rsslim", "startcode", "endcode", "startstack", "kstkesp", "kstkeip", "signal", "blocked", "sigignore", "sigcatch", "wchan", "nswap", "cnswap", "exit_signal", "processor", "rt_priority", "policy", "delayacct_blkio_ticks", "guest_time", "cguest_time", "start_data", "end_data", "start_brk", "arg_start", "arg_end", "env_start", "env_end", "exit_code"}
+
+// parseProcPidStat parses /proc/[pid]/stat. The command name may contain
+// spaces and parentheses, so it is taken up to the last closing parenthesis.
+func parseProcPidStat(r io.Reader) (map[string]interface{}, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	s := strings.TrimSpace(string(b))
+
+	open := strings.Index(s, " (")
+	closing := strings.LastIndex(s, ")")
+	if open < 0 || closing < open {
+		return nil, errors.New("unknown /proc/[pid]/stat format")
+	}
+
+	result := make(map[string]interface{})
+	result["pid"], err = strconv.ParseInt(s[:open], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	result["comm"] = s[open+2 : closing]
+
+	columns, _ := splitColumnsBySpace(s[closing+1:])
+	for i, col := range columns {
+		if i >= len(procPidStatLabels) {
+			break
+		}
+		label := procPidStatLabels[i]
+		if label == "state" {
+			result[label] = col
+			continue
+		}
+
+		if val, err := strconv.ParseInt(col, 10, 64); err == nil {
+			result[label] = val
+			continue
+		}
+		result[label], err = parseUnsignedInteger(col, 10)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,117 @@
+//go:build linux
+
+package parser
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var procPidStatusIDLabels = []string{"real", "effective", "saved_set", "filesystem"}
+
+func parseProcPidStatusValue(key, valueStr string) (interface{}, error) {
+	switch key {
+
+	// strings which look like integers
+	case
+		"CapAmb",       // hex
+		"CapBnd",       // hex
+		"CapEff",       // hex
+		"CapInh",       // hex
+		"CapPrm",       // hex
+		"Cpus_allowed", // hex
+		"Mems_allowed", // hex
+		"ShdPnd",       // hex
+		"SigBlk",       // hex
+		"SigCgt",       // hex
+		"SigIgn",       // hex
+		"SigPnd",       // hex
+		"Umask":        // octal
+
+		return valueStr, nil
+
+	// real, effective, saved set and filesystem IDs
+	case
+		"Gid",
+		"Uid":
+
+		columns, _ := splitColumnsBySpace(valueStr)
+		if len(columns) != len(procPidStatusIDLabels) {
+			return nil, errors.Errorf("unknown /proc/[pid]/status format: %s: %s", key, valueStr)
+		}
+		result := make(map[string]interface{})
+		for i, label := range procPidStatusIDLabels {
+			val, err := strconv.ParseInt(columns[i], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			result[label] = val
+		}
+		return result, nil
+
+	// space separated integers
+	case
+		"Groups",
+		"NSpgid",
+		"NSpid",
+		"NSsid",
+		"NStgid":
+
+		result := []interface{}{}
+		for _, col := range strings.Fields(valueStr) {
+			val, err := strconv.ParseInt(col, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, val)
+		}
+		return result, nil
+
+	// lists of ranges
+	case
+		"Cpus_allowed_list",
+		"Mems_allowed_list":
+
+		return parseRangeList(valueStr)
+
+	// queued/limit
+	case
+		"SigQ":
+
+		queuedStr, limitStr, ok := strings.Cut(valueStr, "/")
+		if !ok {
+			return nil, errors.Errorf("unknown /proc/[pid]/status format: %s: %s", key, valueStr)
+		}
+		queued, err := strconv.ParseInt(queuedStr, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		limit, err := strconv.ParseInt(limitStr, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"queued": queued,
+			"limit":  limit,
+		}, nil
+	}
+
+	if strings.HasSuffix(valueStr, " kB") {
+		val, err := strconv.ParseInt(strings.TrimSpace(strings.TrimSuffix(valueStr, " kB")), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"value": val,
+			"unit":  "kB",
+		}, nil
+	}
+
+	if isLikelyInteger(valueStr) {
+		return strconv.ParseInt(valueStr, 10, 64)
+	}
+
+	return valueStr, nil
+}
@@ -0,0 +1,51 @@
+//go:build linux
+
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// parseAllTasks expands /proc/[pid]/task/*/<file> into all threads of the
+// process, each annotated with its tid and name.
+func parseAllTasks(in *Input) (interface{}, error) {
+	root, pid, subpath := in.Captures["root"], in.Captures["pid"], in.Captures["file"]
+	taskDir := filepath.Join(root, "/proc", pid, "task")
+	entries, err := os.ReadDir(taskDir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []interface{}{}
+	for _, entry := range entries {
+		tid, err := strconv.ParseInt(entry.Name(), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		content, err := parse(filepath.Join(taskDir, entry.Name(), subpath), nil, in.options())
+		if err != nil {
+			// the thread has exited in the meantime
+			if _, statErr := os.Stat(filepath.Join(taskDir, entry.Name())); os.IsNotExist(statErr) {
+				continue
+			}
+			return nil, err
+		}
+
+		name := ""
+		if b, err := os.ReadFile(filepath.Join(taskDir, entry.Name(), "comm")); err == nil {
+			name = strings.TrimSpace(string(b))
+		}
+
+		result = append(result, map[string]interface{}{
+			"tid":     tid,
+			"name":    name,
+			"content": content,
+		})
+	}
+
+	return result, nil
+}
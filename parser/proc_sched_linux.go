@@ -0,0 +1,375 @@
+//go:build linux
+
+package parser
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var procSchedstatCPULabels = []string{"yld_count", "", "sched_count", "sched_goidle", "ttwu_count", "ttwu_local", "rq_cpu_time", "run_delay", "pcount"}
+
+var procSchedstatLoadBalanceLabels = map[int64][]string{
+	15: {"lb_count", "lb_balanced", "lb_failed", "lb_imbalance", "lb_gained", "lb_hot_gained", "lb_nobusyq", "lb_nobusyg"},
+	16: {"lb_count", "lb_balanced", "lb_failed", "lb_imbalance", "lb_gained", "lb_hot_gained", "lb_nobusyq", "lb_nobusyg"},
+	17: {"lb_count", "lb_balanced", "lb_failed", "lb_imbalance_load", "lb_imbalance_util", "lb_imbalance_task", "lb_imbalance_misfit", "lb_gained", "lb_hot_gained", "lb_nobusyq", "lb_nobusyg"},
+}
+
+// the order of the idle types has been changed in version 16
+var procSchedstatIdleTypes = map[int64][]string{
+	15: {"idle", "busy", "newly_idle"},
+	16: {"busy", "idle", "newly_idle"},
+	17: {"busy", "idle", "newly_idle"},
+}
+
+var procSchedstatDomainLabels = []string{"alb_count", "alb_failed", "alb_pushed", "sbe_count", "sbe_balanced", "sbe_pushed", "sbf_count", "sbf_balanced", "sbf_pushed", "ttwu_wake_remote", "ttwu_move_affine", "ttwu_move_balance"}
+
+func parseProcSchedstat(r io.Reader) (map[string]interface{}, error) {
+	lines, err := readAllLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	cpus := []interface{}{}
+	var version int64
+	var cpu map[string]interface{}
+	for _, line := range lines {
+		columns, _ := splitColumnsBySpace(line)
+		if len(columns) < 2 {
+			continue
+		}
+
+		switch {
+		case columns[0] == "version":
+			version, err = strconv.ParseInt(columns[1], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := procSchedstatLoadBalanceLabels[version]; !ok {
+				return nil, errors.Errorf("unsupported /proc/schedstat version: %d", version)
+			}
+			result["version"] = version
+		case columns[0] == "timestamp":
+			result["timestamp"], err = strconv.ParseInt(columns[1], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(columns[0], "cpu"):
+			cpu, err = parseProcSchedstatCPU(columns)
+			if err != nil {
+				return nil, err
+			}
+			cpus = append(cpus, cpu)
+		case strings.HasPrefix(columns[0], "domain"):
+			if cpu == nil {
+				return nil, errors.New("unknown /proc/schedstat format: domain line without preceding cpu line")
+			}
+			domain, err := parseProcSchedstatDomain(version, columns)
+			if err != nil {
+				return nil, err
+			}
+			domains, _ := cpu["domains"].([]interface{})
+			cpu["domains"] = append(domains, domain)
+		}
+	}
+	result["cpus"] = cpus
+
+	return result, nil
+}
+
+func parseProcSchedstatCPU(columns []string) (map[string]interface{}, error) {
+	if len(columns) < len(procSchedstatCPULabels)+1 {
+		return nil, errors.Errorf("unknown /proc/schedstat format. expected %d columns for cpu but got %d columns", len(procSchedstatCPULabels)+1, len(columns))
+	}
+
+	id, err := strconv.ParseInt(strings.TrimPrefix(columns[0], "cpu"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"cpu":     id,
+		"domains": []interface{}{},
+	}
+	for i, label := range procSchedstatCPULabels {
+		// the legacy array expiration count is always 0
+		if label == "" {
+			continue
+		}
+		result[label], err = strconv.ParseInt(columns[i+1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func parseProcSchedstatDomain(version int64, columns []string) (map[string]interface{}, error) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(columns[0], "domain"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{
+		"domain": id,
+	}
+	columns = columns[1:]
+
+	// the name of the domain has been added in version 17
+	if version >= 17 && len(columns) > 0 {
+		result["name"] = columns[0]
+		columns = columns[1:]
+	}
+
+	lbLabels := procSchedstatLoadBalanceLabels[version]
+	idleTypes := procSchedstatIdleTypes[version]
+	expected := 1 + len(idleTypes)*len(lbLabels) + len(procSchedstatDomainLabels)
+	if len(columns) < expected {
+		return nil, errors.Errorf("unknown /proc/schedstat format. expected %d columns for domain but got %d columns", expected, len(columns))
+	}
+
+	result["cpumask"] = columns[0]
+	columns = columns[1:]
+
+	for _, idleType := range idleTypes {
+		lb := make(map[string]interface{})
+		for _, label := range lbLabels {
+			lb[label], err = strconv.ParseInt(columns[0], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			columns = columns[1:]
+		}
+		result[idleType] = lb
+	}
+
+	for i, label := range procSchedstatDomainLabels {
+		result[label], err = strconv.ParseInt(columns[i], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+var reProcPidSchedHeader = regexp.MustCompile(`^(.*) \((\d+), #threads: (\d+)\)$`)
+
+// parseProcPidSched parses /proc/[pid]/sched. The header line such as
+// "bash (1234, #threads: 1)" is parsed into comm, pid and threads.
+func parseProcPidSched(r io.Reader) (map[string]interface{}, error) {
+	lines, err := readAllLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	for i, line := range lines {
+		if i == 0 {
+			submatch := reProcPidSchedHeader.FindStringSubmatch(line)
+			if len(submatch) != 4 {
+				return nil, errors.Errorf("unknown /proc/[pid]/sched header: %s", line)
+			}
+			result["comm"] = submatch[1]
+			result["pid"], _ = strconv.ParseInt(submatch[2], 10, 64)
+			result["threads"], _ = strconv.ParseInt(submatch[3], 10, 64)
+			continue
+		}
+
+		if line == "" || strings.HasPrefix(line, "---") {
+			continue
+		}
+
+		if key, valueStr, ok := strings.Cut(line, ":"); ok {
+			key = strings.TrimSpace(key)
+			result[key] = parseNumberOrString(strings.TrimSpace(valueStr))
+			continue
+		}
+
+		// NUMA statistics such as "current_node=0, numa_group_id=0" or
+		// "numa_faults node=0 task_private=0 task_shared=0 ..."
+		name, pairs, ok := strings.Cut(line, " ")
+		if ok && !strings.Contains(name, "=") {
+			faults, _ := result[name].([]interface{})
+			result[name] = append(faults, parseKeyEqualsValuePairs(pairs))
+			continue
+		}
+		for key, val := range parseKeyEqualsValuePairs(line) {
+			result[key] = val
+		}
+	}
+
+	return result, nil
+}
+
+func parseKeyEqualsValuePairs(s string) map[string]interface{} {
+	result := make(map[string]interface{})
+	for _, field := range strings.Fields(strings.ReplaceAll(s, ",", " ")) {
+		key, valueStr, ok := strings.Cut(field, "=")
+		if !ok {
+			continue
+		}
+		result[key] = parseNumberOrString(valueStr)
+	}
+	return result
+}
+
+func parseProcPidSchedstat(r io.Reader) (map[string]interface{}, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, _ := splitColumnsBySpace(string(b))
+	if len(columns) < 3 {
+		return nil, errors.Errorf("unknown /proc/[pid]/schedstat format. expected 3 columns but got %d columns", len(columns))
+	}
+
+	result := make(map[string]interface{})
+	for i, label := range []string{"run_time_ns", "wait_time_ns", "timeslices"} {
+		result[label], err = strconv.ParseInt(columns[i], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+var (
+	reProcSchedDebugVersion = regexp.MustCompile(`^Sched Debug Version: ([^,]+), (.+)$`)
+	reProcSchedDebugCPU     = regexp.MustCompile(`^cpu#(\d+)(?:, ([\d.]+) MHz)?$`)
+	reProcSchedDebugRq      = regexp.MustCompile(`^(cfs_rq|rt_rq|dl_rq)\[(\d+)\]:(.*)$`)
+)
+
+// parseProcSchedDebug parses /proc/sched_debug (or
+// /sys/kernel/debug/sched/debug on newer kernels) into the global values, the
+// sysctl_sched section and per-CPU sections with their run queues and
+// runnable tasks.
+func parseProcSchedDebug(r io.Reader) (map[string]interface{}, error) {
+	lines, err := readAllLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	cpus := []interface{}{}
+	var cpu, section map[string]interface{}
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		if submatch := reProcSchedDebugVersion.FindStringSubmatch(line); len(submatch) == 3 {
+			result["version"] = submatch[1]
+			result["kernel"] = submatch[2]
+			continue
+		}
+
+		if trimmed == "sysctl_sched" {
+			section = make(map[string]interface{})
+			result["sysctl_sched"] = section
+			continue
+		}
+
+		if submatch := reProcSchedDebugCPU.FindStringSubmatch(trimmed); len(submatch) == 3 {
+			id, _ := strconv.ParseInt(submatch[1], 10, 64)
+			cpu = map[string]interface{}{
+				"cpu":    id,
+				"cfs_rq": []interface{}{},
+			}
+			if submatch[2] != "" {
+				cpu["mhz"], _ = strconv.ParseFloat(submatch[2], 64)
+			}
+			cpus = append(cpus, cpu)
+			section = cpu
+			continue
+		}
+
+		if submatch := reProcSchedDebugRq.FindStringSubmatch(trimmed); len(submatch) == 4 && cpu != nil {
+			section = make(map[string]interface{})
+			if submatch[1] == "cfs_rq" {
+				section["path"] = submatch[3]
+				cpu["cfs_rq"] = append(cpu["cfs_rq"].([]interface{}), section)
+			} else {
+				cpu[submatch[1]] = section
+			}
+			continue
+		}
+
+		if trimmed == "runnable tasks:" {
+			var tasks []interface{}
+			tasks, i = parseProcSchedDebugTasks(lines, i+1)
+			if cpu != nil {
+				cpu["runnable_tasks"] = tasks
+			}
+			section = nil
+			continue
+		}
+
+		key, valueStr, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		val := parseNumberOrString(strings.TrimSpace(valueStr))
+		if strings.HasPrefix(line, " ") {
+			if section != nil {
+				section[strings.TrimPrefix(strings.TrimSpace(key), ".")] = val
+			}
+			continue
+		}
+		result[strings.TrimSpace(key)] = val
+		section = nil
+	}
+	result["cpus"] = cpus
+
+	return result, nil
+}
+
+// parseProcSchedDebugTasks parses the table of runnable tasks starting at
+// lines[start] (i.e. its header) and returns the index of the last line of
+// the table. The columns vary between kernel versions, so they are named
+// after the header and the columns without header are kept in "extra".
+func parseProcSchedDebugTasks(lines []string, start int) ([]interface{}, int) {
+	tasks := []interface{}{}
+	if start >= len(lines) {
+		return tasks, start
+	}
+	header, _ := splitColumnsBySpace(lines[start])
+
+	i := start + 1
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, "---") {
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			break
+		}
+
+		task := make(map[string]interface{})
+		if strings.HasPrefix(line, ">") {
+			task["current"] = true
+			line = " " + line[1:]
+		}
+		columns, _ := splitColumnsBySpace(line)
+		for j, col := range columns {
+			if j < len(header) {
+				task[header[j]] = parseNumberOrString(col)
+				continue
+			}
+			extra, _ := task["extra"].([]interface{})
+			task["extra"] = append(extra, col)
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, i
+}
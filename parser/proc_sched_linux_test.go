@@ -0,0 +1,198 @@
+//go:build linux
+
+package parser
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// seq returns n space separated integers starting at 1.
+func seq(n int) string {
+	nums := make([]string, n)
+	for i := range nums {
+		nums[i] = strconv.Itoa(i + 1)
+	}
+	return strings.Join(nums, " ")
+}
+
+// dig returns the value at path in v, where an element of path is either a
+// key of an object or an index of an array.
+func dig(v interface{}, path ...interface{}) interface{} {
+	for _, p := range path {
+		switch p := p.(type) {
+		case string:
+			m, _ := v.(map[string]interface{})
+			v = m[p]
+		case int:
+			a, _ := v.([]interface{})
+			if p >= len(a) {
+				return nil
+			}
+			v = a[p]
+		}
+	}
+	return v
+}
+
+type digTest struct {
+	path []interface{}
+	want interface{}
+}
+
+func TestParseProcSchedstat(t *testing.T) {
+	const cpuLine = "cpu0 0 0 10 11 12 13 14 15 16\n"
+	domain := func(path ...interface{}) []interface{} {
+		return append([]interface{}{"cpus", 0, "domains", 0}, path...)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  []digTest
+	}{
+		{
+			name: "version 15",
+			// 3 idle types of 8 load balance counts, then 12 domain counts
+			input: "version 15\ntimestamp 4294892296\n" + cpuLine + "domain0 00000003 " + seq(36) + "\n",
+			want: []digTest{
+				{[]interface{}{"version"}, int64(15)},
+				{[]interface{}{"timestamp"}, int64(4294892296)},
+				{[]interface{}{"cpus", 0, "cpu"}, int64(0)},
+				{[]interface{}{"cpus", 0, "yld_count"}, int64(0)},
+				{[]interface{}{"cpus", 0, "sched_count"}, int64(10)},
+				{[]interface{}{"cpus", 0, "pcount"}, int64(16)},
+				{domain("domain"), int64(0)},
+				{domain("name"), nil},
+				{domain("cpumask"), "00000003"},
+				{domain("idle", "lb_count"), int64(1)},
+				{domain("idle", "lb_nobusyg"), int64(8)},
+				{domain("busy", "lb_count"), int64(9)},
+				{domain("newly_idle", "lb_nobusyg"), int64(24)},
+				{domain("alb_count"), int64(25)},
+				{domain("ttwu_move_balance"), int64(36)},
+			},
+		},
+		{
+			name: "version 17",
+			// the name, 3 idle types of 11 load balance counts, then 12
+			// domain counts
+			input: "version 17\ntimestamp 4294892296\n" + cpuLine + "domain0 MC 00000003 " + seq(45) + "\n",
+			want: []digTest{
+				{[]interface{}{"version"}, int64(17)},
+				{domain("name"), "MC"},
+				{domain("cpumask"), "00000003"},
+				{domain("busy", "lb_count"), int64(1)},
+				{domain("busy", "lb_imbalance_load"), int64(4)},
+				{domain("busy", "lb_imbalance_misfit"), int64(7)},
+				{domain("busy", "lb_nobusyg"), int64(11)},
+				{domain("idle", "lb_count"), int64(12)},
+				{domain("newly_idle", "lb_nobusyg"), int64(33)},
+				{domain("alb_count"), int64(34)},
+				{domain("ttwu_move_balance"), int64(45)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseProcSchedstat(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, d := range tt.want {
+				if v := dig(got, d.path...); !reflect.DeepEqual(v, d.want) {
+					t.Errorf("%v: got %#v, want %#v", d.path, v, d.want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseProcSchedstatErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "unsupported version", input: "version 14\n"},
+		{name: "domain without cpu", input: "version 15\ndomain0 00000003 " + seq(36) + "\n"},
+		{name: "short domain", input: "version 17\ncpu0 0 0 10 11 12 13 14 15 16\ndomain0 MC 00000003 " + seq(36) + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseProcSchedstat(strings.NewReader(tt.input)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+const testSchedDebug = `Sched Debug Version: v0.11, 5.15.0-91-generic #101-Ubuntu
+ktime                                   : 4383265.162054
+jiffies                                 : 4295988564
+
+sysctl_sched
+  .sysctl_sched_latency                    : 24.000000
+  .sysctl_sched_tunable_scaling            : 1 (logarithmic)
+
+cpu#0, 2400.000 MHz
+  .nr_running                    : 1
+  .curr->pid                     : 42
+
+cfs_rq[0]:/
+  .exec_clock                    : 0.000000
+  .nr_running                    : 1
+
+cfs_rq[0]:/user.slice
+  .nr_running                    : 0
+
+rt_rq[0]:
+  .rt_nr_running                 : 0
+
+runnable tasks:
+ S            task   PID         tree-key  switches  prio     wait-time             sum-exec        sum-sleep
+-------------------------------------------------------------------------------------------------------------
+ S        systemd     1        10.000000      1000   120         0.000000       100.000000         0.000000 0 0 /
+>R           bash    42        20.500000        10   120         0.000000         1.000000         0.000000 0 0 /user.slice
+
+cpu#1, 2400.000 MHz
+  .nr_running                    : 0
+`
+
+func TestParseProcSchedDebug(t *testing.T) {
+	got, err := parseProcSchedDebug(strings.NewReader(testSchedDebug))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []digTest{
+		{[]interface{}{"version"}, "v0.11"},
+		{[]interface{}{"kernel"}, "5.15.0-91-generic #101-Ubuntu"},
+		{[]interface{}{"jiffies"}, int64(4295988564)},
+		{[]interface{}{"sysctl_sched", "sysctl_sched_latency"}, 24.0},
+		{[]interface{}{"sysctl_sched", "sysctl_sched_tunable_scaling"}, "1 (logarithmic)"},
+		{[]interface{}{"cpus", 0, "cpu"}, int64(0)},
+		{[]interface{}{"cpus", 0, "mhz"}, 2400.0},
+		{[]interface{}{"cpus", 0, "curr->pid"}, int64(42)},
+		{[]interface{}{"cpus", 0, "cfs_rq", 0, "path"}, "/"},
+		{[]interface{}{"cpus", 0, "cfs_rq", 0, "nr_running"}, int64(1)},
+		{[]interface{}{"cpus", 0, "cfs_rq", 1, "path"}, "/user.slice"},
+		{[]interface{}{"cpus", 0, "rt_rq", "rt_nr_running"}, int64(0)},
+		{[]interface{}{"cpus", 0, "runnable_tasks", 0, "task"}, "systemd"},
+		{[]interface{}{"cpus", 0, "runnable_tasks", 0, "current"}, nil},
+		{[]interface{}{"cpus", 0, "runnable_tasks", 1, "PID"}, int64(42)},
+		{[]interface{}{"cpus", 0, "runnable_tasks", 1, "tree-key"}, 20.5},
+		{[]interface{}{"cpus", 0, "runnable_tasks", 1, "current"}, true},
+		{[]interface{}{"cpus", 0, "runnable_tasks", 1, "extra"}, []interface{}{"0", "0", "/user.slice"}},
+		{[]interface{}{"cpus", 1, "cpu"}, int64(1)},
+		{[]interface{}{"cpus", 1, "nr_running"}, int64(0)},
+	}
+	for _, tt := range tests {
+		if v := dig(got, tt.path...); !reflect.DeepEqual(v, tt.want) {
+			t.Errorf("%v: got %#v, want %#v", tt.path, v, tt.want)
+		}
+	}
+}
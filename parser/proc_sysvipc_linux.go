@@ -0,0 +1,120 @@
+//go:build linux
+
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+var procSysvipcTableParser = createTableParser(skipTableHeader(1), createTableRowParser(splitColumnsBySpace, createHeaderKeyedColumnsParser(parseProcSysvipcValue)))
+
+func parseProcSysvipcValue(key, valueStr string) (interface{}, error) {
+	// permissions are printed in octal, so keep them as they are
+	if key == "perms" {
+		return valueStr, nil
+	}
+
+	return strconv.ParseInt(valueStr, 10, 64)
+}
+
+// parseProcSysvipcDir combines the SysV IPC tables with the POSIX IPC objects
+// so that all IPC objects on the host can be seen at once.
+func parseProcSysvipcDir(dir string) (interface{}, error) {
+	result := make(map[string]interface{})
+	for _, name := range []string{"shm", "sem", "msg"} {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		table, err := procSysvipcTableParser(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		if table == nil {
+			table = []interface{}{}
+		}
+		result[name] = table
+	}
+
+	for name, dir := range map[string]string{"posix_shm": "/dev/shm", "posix_mqueue": "/dev/mqueue"} {
+		entries, err := parsePosixIPCDir(dir)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				entries = []interface{}{}
+			} else {
+				return nil, err
+			}
+		}
+		result[name] = entries
+	}
+
+	return result, nil
+}
+
+// parsePosixIPCDir lists POSIX shared memory objects (/dev/shm) or message
+// queues (/dev/mqueue) with their size and owner.
+func parsePosixIPCDir(dir string) (interface{}, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []interface{}{}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		obj := map[string]interface{}{
+			"name": entry.Name(),
+			"size": info.Size(),
+			"mode": fmt.Sprintf("%04o", info.Mode().Perm()),
+		}
+		if st, ok := info.Sys().(*syscall.Stat_t); ok {
+			obj["uid"] = int64(st.Uid)
+			obj["gid"] = int64(st.Gid)
+		}
+
+		if filepath.Clean(dir) == "/dev/mqueue" && info.Mode().IsRegular() {
+			if b, err := os.ReadFile(filepath.Join(dir, entry.Name())); err == nil {
+				for key, val := range parseMqueueStatus(string(b)) {
+					obj[key] = val
+				}
+			}
+		}
+
+		result = append(result, obj)
+	}
+
+	return result, nil
+}
+
+// parseMqueueStatus parses the content of a message queue in /dev/mqueue such
+// as "QSIZE:0          NOTIFY:0     SIGNO:0     NOTIFY_PID:0".
+func parseMqueueStatus(s string) map[string]interface{} {
+	result := make(map[string]interface{})
+	for _, field := range strings.Fields(s) {
+		key, valueStr, ok := strings.Cut(field, ":")
+		if !ok {
+			continue
+		}
+		val, err := strconv.ParseInt(valueStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		result[strings.ToLower(key)] = val
+	}
+	return result
+}
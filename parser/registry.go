@@ -0,0 +1,256 @@
+package parser
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PatternKind tells how the pattern of a format is matched with file names.
+type PatternKind int
+
+const (
+	// PatternLiteral matches the file name as it is.
+	PatternLiteral PatternKind = iota
+	// PatternGlob matches by path.Match (e.g. "/sys/class/net/*").
+	PatternGlob
+	// PatternRegexp matches by a regular expression, whose named groups
+	// (e.g. (?P<pid>\d+)) are passed to the format as Input.Captures.
+	PatternRegexp
+)
+
+func (k PatternKind) String() string {
+	switch k {
+	case PatternLiteral:
+		return "literal"
+	case PatternGlob:
+		return "glob"
+	case PatternRegexp:
+		return "regexp"
+	}
+	return "unknown"
+}
+
+// Input is a file to be parsed by a registered format.
+type Input struct {
+	// Name is the file name as given.
+	Name string
+	// Reader reads the content of the file. It is nil for formats which
+	// read the file system by Name.
+	Reader io.Reader
+	// Captures are the named groups matched by PatternRegexp.
+	Captures map[string]string
+	// Options are the options given to ParseWithOptions.
+	Options *Options
+
+	// opened is true if Reader is the file named Name opened by parse,
+	// rather than a reader given by the caller (e.g. stdin).
+	opened bool
+}
+
+// options returns the options of in, which are the zero value if not given.
+func (in *Input) options() *Options {
+	if in.Options == nil {
+		return &Options{}
+	}
+	return in.Options
+}
+
+// Format is a parser for the files which match Pattern.
+type Format struct {
+	// Name is a short name of the format (e.g. "meminfo"), which can be
+	// given to Parse instead of a file name.
+	Name        string
+	Pattern     string
+	Kind        PatternKind
+	Description string
+	// ReadsPath is true if the format reads the file system by Input.Name
+	// (e.g. directories and virtual paths such as /proc/*/ns) rather than
+	// Input.Reader. Such formats cannot parse a reader.
+	ReadsPath bool
+	Parse     func(in *Input) (interface{}, error)
+
+	re *regexp.Regexp
+}
+
+var registry []*Format
+
+// Register registers a format. Formats registered later take precedence over
+// the ones registered earlier (including the built-in ones) for the file
+// names which match both. It panics if the pattern is invalid.
+func Register(f Format) {
+	switch f.Kind {
+	case PatternGlob:
+		if _, err := path.Match(f.Pattern, ""); err != nil {
+			panic(errors.Wrapf(err, "invalid pattern of format %s", f.Name))
+		}
+	case PatternRegexp:
+		re, err := regexp.Compile(f.Pattern)
+		if err != nil {
+			panic(errors.Wrapf(err, "invalid pattern of format %s", f.Name))
+		}
+		f.re = re
+	}
+
+	registry = append(registry, &f)
+}
+
+// Formats returns the registered formats in the order of registration.
+func Formats() []Format {
+	result := make([]Format, 0, len(registry))
+	for _, f := range registry {
+		result = append(result, *f)
+	}
+	return result
+}
+
+// lookupFormat returns the format for fname and the captures of its pattern,
+// or nil if there is no format for fname. Formats which read the file system
+// are skipped unless readsPath is true, so that a reader is not taken by them
+// (e.g. the one for any directory).
+func lookupFormat(fname string, readsPath bool) (*Format, map[string]string) {
+	for i := len(registry) - 1; i >= 0; i-- {
+		f := registry[i]
+		if f.ReadsPath && !readsPath {
+			continue
+		}
+		switch f.Kind {
+		case PatternLiteral:
+			if fname == f.Pattern {
+				return f, map[string]string{}
+			}
+
+		case PatternGlob:
+			if matched, _ := path.Match(f.Pattern, fname); matched {
+				return f, map[string]string{}
+			}
+
+		case PatternRegexp:
+			submatch := f.re.FindStringSubmatch(fname)
+			if submatch == nil {
+				continue
+			}
+			captures := make(map[string]string)
+			for j, name := range f.re.SubexpNames() {
+				if name != "" {
+					captures[name] = submatch[j]
+				}
+			}
+			return f, captures
+		}
+	}
+
+	return nil, nil
+}
+
+// lookupFormatByName returns the format named name, or nil if there is none.
+func lookupFormatByName(name string) *Format {
+	for i := len(registry) - 1; i >= 0; i-- {
+		if registry[i].Name == name {
+			return registry[i]
+		}
+	}
+	return nil
+}
+
+// Parse parses the file named name by the registered format for it. If r is
+// not nil, the content is read from r instead of the file, and name can also
+// be the name of a format (e.g. "meminfo") or a file name relative to /proc
+// (e.g. "self/status").
+func Parse(name string, r io.Reader) (interface{}, error) {
+	return ParseWithOptions(name, r, Options{})
+}
+
+// ParseWithOptions is Parse with options.
+func ParseWithOptions(name string, r io.Reader, opts Options) (interface{}, error) {
+	v, err := parse(name, r, &opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.QueryFriendly {
+		v = makeKeysQueryFriendly(v)
+	}
+	return v, nil
+}
+
+func parse(name string, r io.Reader, opts *Options) (interface{}, error) {
+	if opts.As != "" {
+		if r == nil {
+			f, err := os.Open(name)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			r = f
+		}
+		return parseAs(r, opts)
+	}
+
+	var f *Format
+	captures := map[string]string{}
+	if r != nil {
+		f = lookupFormatByName(name)
+	}
+	if f == nil {
+		// relative names are relative to /proc for a reader (e.g.
+		// self/status), or to the working directory for a file
+		fname := name
+		if !strings.HasPrefix(fname, "/") {
+			if r != nil {
+				fname = path.Join("/proc", fname)
+			} else if abs, err := filepath.Abs(fname); err == nil {
+				fname = abs
+			}
+		}
+		f, captures = lookupFormat(path.Clean(fname), r == nil)
+	}
+	if f == nil {
+		return nil, errors.Errorf("%s is not supported", name)
+	}
+
+	in := &Input{Name: name, Reader: r, Captures: captures, Options: opts}
+	if f.ReadsPath {
+		if r != nil {
+			return nil, errors.Errorf("%s cannot be parsed from a reader", name)
+		}
+	} else if r == nil {
+		file, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		in.Reader = file
+		in.opened = true
+	}
+
+	return f.Parse(in)
+}
+
+func mapParser(parser func(io.Reader) (map[string]interface{}, error)) func(*Input) (interface{}, error) {
+	return func(in *Input) (interface{}, error) {
+		return parser(in.Reader)
+	}
+}
+
+func arrayParser(parser func(io.Reader) ([]interface{}, error)) func(*Input) (interface{}, error) {
+	return func(in *Input) (interface{}, error) {
+		return parser(in.Reader)
+	}
+}
+
+func tableParser(parser tableParserFn) func(*Input) (interface{}, error) {
+	return func(in *Input) (interface{}, error) {
+		return parser(in.Reader)
+	}
+}
+
+func pathParser(parser func(string) (interface{}, error)) func(*Input) (interface{}, error) {
+	return func(in *Input) (interface{}, error) {
+		return parser(in.Name)
+	}
+}
@@ -0,0 +1,149 @@
+//go:build linux
+
+package parser
+
+import (
+	"os"
+)
+
+// procRootPattern matches /proc, optionally under a root (captured as root)
+// such as /host where the host's /proc is mounted in a container.
+const procRootPattern = `^(?P<root>(?:/.+)?)/proc/`
+
+// procPidPattern returns a pattern of a file in /proc/<pid> (or
+// /proc/<pid>/task/<tid>) for PatternRegexp.
+func procPidPattern(file string) string {
+	return procRootPattern + `(?P<pid>\d+|self)/(?:task/(?P<tid>\d+)/)?` + file + `$`
+}
+
+// procNetPattern returns a pattern of a file in /proc/net (or
+// /proc/<pid>/net) for PatternRegexp.
+func procNetPattern(file string) string {
+	return procRootPattern + `(?:(?P<pid>\d+|self)/)?net/` + file + `$`
+}
+
+// The built-in formats. As formats registered later take precedence, the
+// generic ones come first (e.g. net/vlan before net/vlan/config).
+func init() {
+	for _, p := range []Format{
+		{Name: "directory", Kind: PatternRegexp, Pattern: `^/.*$`, ReadsPath: true, Description: "any other directory as nested objects of file values up to --depth", Parse: parseGenericDir},
+
+		{Name: "cpuinfo", Kind: PatternLiteral, Pattern: "/proc/cpuinfo", Description: "CPUs", Parse: arrayParser(createChunkParser(createLineParser(splitLineByColon, parseProcCpuinfoValue)))},
+		{Name: "crypto", Kind: PatternLiteral, Pattern: "/proc/crypto", Description: "ciphers in the kernel crypto API", Parse: arrayParser(createChunkParser(createLineParser(splitLineByColon, parseProcCryptoValue)))},
+		{Name: "diskstats", Kind: PatternLiteral, Pattern: "/proc/diskstats", Description: "I/O statistics of block devices", Parse: tableParser(createTableParser(noTableHeader, createTableRowParser(splitColumnsBySpace, parseProcDiskstatsColumns)))},
+		{Name: "iomem", Kind: PatternLiteral, Pattern: "/proc/iomem", Description: "physical memory map (nested, or flattened by --flat)", Parse: parseProcIomem},
+		{Name: "ioports", Kind: PatternLiteral, Pattern: "/proc/ioports", Description: "I/O port regions (nested, or flattened by --flat)", Parse: parseProcIomem},
+		{Name: "meminfo", Kind: PatternLiteral, Pattern: "/proc/meminfo", Description: "memory usage", Parse: mapParser(createMapParser(createLineParser(splitLineByColon, parseProcMeminfoValue)))},
+		{Name: "modules", Kind: PatternLiteral, Pattern: "/proc/modules", Description: "loaded modules (with parameters by --module-parameters)", Parse: parseProcModules},
+		{Name: "mounts", Kind: PatternLiteral, Pattern: "/proc/mounts", Description: "mounted file systems", Parse: tableParser(createTableParser(noTableHeader, createTableRowParser(splitColumnsBySpace, parseProcMountsColumns)))},
+		{Name: "sched_debug", Kind: PatternLiteral, Pattern: "/proc/sched_debug", Description: "scheduler debug information", Parse: mapParser(parseProcSchedDebug)},
+		{Name: "debug/sched/debug", Kind: PatternLiteral, Pattern: "/sys/kernel/debug/sched/debug", Description: "scheduler debug information in debugfs", Parse: mapParser(parseProcSchedDebug)},
+		{Name: "schedstat", Kind: PatternLiteral, Pattern: "/proc/schedstat", Description: "scheduler statistics per CPU and domain", Parse: mapParser(parseProcSchedstat)},
+		{Name: "sysvipc", Kind: PatternLiteral, Pattern: "/proc/sysvipc", ReadsPath: true, Description: "System V and POSIX IPC objects", Parse: pathParser(parseProcSysvipcDir)},
+		{Name: "sysvipc/shm", Kind: PatternLiteral, Pattern: "/proc/sysvipc/shm", Description: "System V shared memory segments", Parse: tableParser(procSysvipcTableParser)},
+		{Name: "sysvipc/sem", Kind: PatternLiteral, Pattern: "/proc/sysvipc/sem", Description: "System V semaphore sets", Parse: tableParser(procSysvipcTableParser)},
+		{Name: "sysvipc/msg", Kind: PatternLiteral, Pattern: "/proc/sysvipc/msg", Description: "System V message queues", Parse: tableParser(procSysvipcTableParser)},
+		{Name: "dev/shm", Kind: PatternLiteral, Pattern: "/dev/shm", ReadsPath: true, Description: "POSIX shared memory objects", Parse: pathParser(parsePosixIPCDir)},
+		{Name: "dev/mqueue", Kind: PatternLiteral, Pattern: "/dev/mqueue", ReadsPath: true, Description: "POSIX message queues", Parse: pathParser(parsePosixIPCDir)},
+		{Name: "vmstat", Kind: PatternLiteral, Pattern: "/proc/vmstat", Description: "virtual memory statistics", Parse: mapParser(createMapParser(createLineParser(splitLineBySpace, parseProcVmstatValue)))},
+
+		{Name: "*/ns", Kind: PatternLiteral, Pattern: "/proc/*/ns", ReadsPath: true, Description: "namespaces of all processes", Parse: pathParser(parseAllProcNs)},
+		{Name: "*/attr", Kind: PatternLiteral, Pattern: "/proc/*/attr", ReadsPath: true, Description: "security context of all processes", Parse: pathParser(parseAllProcSecurity)},
+		{Name: "pid", Kind: PatternRegexp, Pattern: procRootPattern + `(?P<pid>\d+|self)$`, ReadsPath: true, Description: "hang report of a process", Parse: pathParser(parseProcPidHangReport)},
+		{Name: "pid/task/*", Kind: PatternRegexp, Pattern: procRootPattern + `(?P<pid>\d+|self)/task/\*/(?P<file>.+)$`, ReadsPath: true, Description: "a file of all threads of a process", Parse: parseAllTasks},
+		{Name: "pid/attr", Kind: PatternRegexp, Pattern: procPidPattern("attr"), ReadsPath: true, Description: "security context of a process", Parse: pathParser(parseProcPidSecurity)},
+		{Name: "pid/attr/current", Kind: PatternRegexp, Pattern: procPidPattern("attr/(?:apparmor/)?current"), Description: "LSM label of a process", Parse: parseProcPidAttrCurrent},
+		{Name: "pid/cgroup", Kind: PatternRegexp, Pattern: procPidPattern("cgroup"), Description: "control groups of a process", Parse: tableParser(createTableParser(noTableHeader, createTableRowParser(splitProcPidCgroupColumns, parseProcPidCgroupColumns)))},
+		{Name: "pid/fd", Kind: PatternRegexp, Pattern: procPidPattern("fd"), ReadsPath: true, Description: "open files of a process", Parse: pathParser(parseProcPidFdDir)},
+		{Name: "pid/fdinfo", Kind: PatternRegexp, Pattern: procPidPattern(`fdinfo/(?P<fd>\d+)`), Description: "details of an open file of a process", Parse: mapParser(parseProcPidFdinfo)},
+		{Name: "pid/io", Kind: PatternRegexp, Pattern: procPidPattern("io"), Description: "I/O statistics of a process", Parse: mapParser(createMapParser(createLineParser(splitLineByColon, parseProcPidIoValue)))},
+		{Name: "pid/limits", Kind: PatternRegexp, Pattern: procPidPattern("limits"), Description: "resource limits of a process", Parse: tableParser(createTableParser(skipTableHeader(1), createTableRowParser(splitProcPidLimitsColumns, parseProcPidLimitsColumns)))},
+		{Name: "pid/loginuid", Kind: PatternRegexp, Pattern: procPidPattern("loginuid"), Description: "login uid of a process", Parse: mapParser(parseProcPidLoginuid)},
+		{Name: "pid/ns", Kind: PatternRegexp, Pattern: procPidPattern("ns"), ReadsPath: true, Description: "namespaces of a process", Parse: pathParser(parseProcPidNsDir)},
+		{Name: "pid/numa_maps", Kind: PatternRegexp, Pattern: procPidPattern("numa_maps"), Description: "NUMA memory policy and pages per node of the mappings of a process", Parse: arrayParser(parseProcPidNumaMaps)},
+		{Name: "pid/personality", Kind: PatternRegexp, Pattern: procPidPattern("personality"), Description: "execution domain of a process", Parse: mapParser(parseProcPidPersonality)},
+		{Name: "pid/sched", Kind: PatternRegexp, Pattern: procPidPattern("sched"), Description: "scheduler statistics of a process", Parse: mapParser(parseProcPidSched)},
+		{Name: "pid/schedstat", Kind: PatternRegexp, Pattern: procPidPattern("schedstat"), Description: "run and wait time of a process", Parse: mapParser(parseProcPidSchedstat)},
+		{Name: "pid/sessionid", Kind: PatternRegexp, Pattern: procPidPattern("sessionid"), Description: "audit session id of a process", Parse: mapParser(parseProcPidSessionid)},
+		{Name: "pid/stack", Kind: PatternRegexp, Pattern: procPidPattern("stack"), Description: "kernel stack of a process", Parse: arrayParser(parseProcPidStack)},
+		{Name: "pid/stat", Kind: PatternRegexp, Pattern: procPidPattern("stat"), Description: "status of a process", Parse: mapParser(parseProcPidStat)},
+		{Name: "pid/status", Kind: PatternRegexp, Pattern: procPidPattern("status"), Description: "status of a process in human readable form", Parse: mapParser(createMapParser(createLineParser(splitLineByFirstColon, parseProcPidStatusValue)))},
+		{Name: "pid/syscall", Kind: PatternRegexp, Pattern: procPidPattern("syscall"), Description: "system call being executed by a process", Parse: mapParser(parseProcPidSyscall)},
+		{Name: "pid/wchan", Kind: PatternRegexp, Pattern: procPidPattern("wchan"), Description: "where a process is waiting in the kernel", Parse: mapParser(parseProcPidWchan)},
+
+		{Name: "net/arp", Kind: PatternRegexp, Pattern: procNetPattern("arp"), Description: "ARP table", Parse: tableParser(createTableParser(skipTableHeader(1), createTableRowParser(splitColumnsBySpace, parseProcNetArpColumns)))},
+		{Name: "net/dev", Kind: PatternRegexp, Pattern: procNetPattern("dev"), Description: "statistics of network interfaces", Parse: tableParser(createTableParser(skipTableHeader(2), createTableRowParser(splitColumnsByColonAndSpace, parseProcNetDevColumns)))},
+		{Name: "net/netlink", Kind: PatternRegexp, Pattern: procNetPattern("netlink"), Description: "netlink sockets", Parse: tableParser(createTableParser(skipTableHeader(1), createTableRowParser(splitColumnsBySpace, parseProcNetNetlinkColumns)))},
+		{Name: "net/protocols", Kind: PatternRegexp, Pattern: procNetPattern("protocols"), Description: "network protocols", Parse: tableParser(createTableParser(skipTableHeader(1), createTableRowParser(splitColumnsBySpace, createHeaderKeyedColumnsParser(parseProcNetProtocolsValue))))},
+		{Name: "net/sockstat", Kind: PatternRegexp, Pattern: procNetPattern("sockstat"), Description: "socket statistics", Parse: mapParser(createMapParser(createLineParser(splitLineByColon, parseProcNetSockstatValue)))},
+		{Name: "net/sockstat6", Kind: PatternRegexp, Pattern: procNetPattern("sockstat6"), Description: "IPv6 socket statistics", Parse: mapParser(createMapParser(createLineParser(splitLineByColon, parseProcNetSockstatValue)))},
+		{Name: "net/softnet_stat", Kind: PatternRegexp, Pattern: procNetPattern("softnet_stat"), Description: "packet processing statistics per CPU", Parse: tableParser(parseProcNetSoftnetStat)},
+		{Name: "net/bonding", Kind: PatternRegexp, Pattern: procNetPattern(`bonding/(?P<iface>[^/]+)`), Description: "bonding interface", Parse: mapParser(parseProcNetBonding)},
+		{Name: "net/vlan", Kind: PatternRegexp, Pattern: procNetPattern(`vlan/(?P<iface>[^/]+)`), Description: "VLAN interface", Parse: mapParser(parseProcNetVlanDevice)},
+		{Name: "net/vlan/config", Kind: PatternRegexp, Pattern: procNetPattern("vlan/config"), Description: "VLAN interfaces", Parse: tableParser(createTableParser(skipTableHeader(2), createTableRowParser(splitColumnsByPipe, parseProcNetVlanConfigColumns)))},
+
+		{Name: "sys/class/net", Kind: PatternLiteral, Pattern: "/sys/class/net", ReadsPath: true, Description: "network interfaces", Parse: pathParser(parseSysClassNetDir)},
+		{Name: "sys/class/net/iface", Kind: PatternGlob, Pattern: "/sys/class/net/*", ReadsPath: true, Description: "a network interface", Parse: pathParser(parseSysClassNetInterface)},
+		{Name: "sys/class/net/bonding_masters", Kind: PatternLiteral, Pattern: "/sys/class/net/bonding_masters", Description: "bonding interfaces", Parse: arrayParser(parseSysClassNetBondingMasters)},
+		{Name: "sys/class/net/iface/statistics", Kind: PatternGlob, Pattern: "/sys/class/net/*/statistics", ReadsPath: true, Description: "statistics of a network interface", Parse: pathParser(parseSysClassNetStatistics)},
+		{Name: "sys/block", Kind: PatternLiteral, Pattern: "/sys/block", ReadsPath: true, Description: "block devices", Parse: pathParser(parseSysBlockDir)},
+		{Name: "sys/block/dev", Kind: PatternGlob, Pattern: "/sys/block/*", ReadsPath: true, Description: "a block device with its queue and partitions", Parse: pathParser(parseSysBlockDevice)},
+		{Name: "sys/block/dev/stat", Kind: PatternRegexp, Pattern: `^/sys/block/(?P<dev>[^/]+)/(?:(?P<part>[^/]+)/)?stat$`, Description: "I/O statistics of a block device or partition", Parse: mapParser(parseSysBlockStat)},
+		{Name: "sys/devices/system/cpu", Kind: PatternLiteral, Pattern: "/sys/devices/system/cpu", ReadsPath: true, Description: "CPU lists, topology, cpufreq, cpuidle and vulnerabilities", Parse: pathParser(parseSysCpuDir)},
+		{Name: "sys/devices/system/cpu/cpu", Kind: PatternRegexp, Pattern: `^/sys/devices/system/cpu/cpu(?P<cpu>\d+)$`, ReadsPath: true, Description: "topology, cpufreq and cpuidle of a CPU", Parse: pathParser(parseSysCpu)},
+		{Name: "sys/devices/system/cpu/list", Kind: PatternRegexp, Pattern: `^/sys/devices/system/cpu/(?:online|offline|possible|present|isolated)$`, Description: "a list of CPUs", Parse: arrayParser(parseSysCpuRangeList)},
+		{Name: "sys/devices/system/cpu/vulnerabilities", Kind: PatternLiteral, Pattern: "/sys/devices/system/cpu/vulnerabilities", ReadsPath: true, Description: "CPU vulnerabilities and their mitigations", Parse: pathParser(parseSysCpuVulnerabilities)},
+		{Name: "sys/devices/system/node", Kind: PatternLiteral, Pattern: "/sys/devices/system/node", ReadsPath: true, Description: "NUMA nodes and their distances", Parse: pathParser(parseSysNodeDir)},
+		{Name: "sys/devices/system/node/node", Kind: PatternRegexp, Pattern: `^/sys/devices/system/node/node(?P<node>\d+)$`, ReadsPath: true, Description: "a NUMA node", Parse: pathParser(parseSysNode)},
+		{Name: "sys/devices/system/node/list", Kind: PatternRegexp, Pattern: `^/sys/devices/system/node/(?:online|possible|has_cpu|has_memory|has_normal_memory)$`, Description: "a list of NUMA nodes", Parse: arrayParser(parseSysCpuRangeList)},
+		{Name: "sys/devices/system/node/node/cpulist", Kind: PatternRegexp, Pattern: `^/sys/devices/system/node/node(?P<node>\d+)/cpulist$`, Description: "CPUs of a NUMA node", Parse: arrayParser(parseSysCpuRangeList)},
+		{Name: "sys/devices/system/node/node/distance", Kind: PatternRegexp, Pattern: `^/sys/devices/system/node/node(?P<node>\d+)/distance$`, Description: "distances from a NUMA node", Parse: arrayParser(parseSysNodeDistanceArray)},
+		{Name: "sys/devices/system/node/node/meminfo", Kind: PatternRegexp, Pattern: `^/sys/devices/system/node/node(?P<node>\d+)/meminfo$`, Description: "memory usage of a NUMA node", Parse: mapParser(createMapParser(createLineParser(splitSysNodeMeminfoLine, parseProcMeminfoValue)))},
+		{Name: "sys/devices/system/node/node/numastat", Kind: PatternRegexp, Pattern: `^/sys/devices/system/node/node(?P<node>\d+)/(?:numastat|vmstat)$`, Description: "NUMA allocation statistics of a NUMA node", Parse: mapParser(createMapParser(createLineParser(splitLineBySpace, parseProcVmstatValue)))},
+		{Name: "sys/class/dmi/id", Kind: PatternLiteral, Pattern: "/sys/class/dmi/id", ReadsPath: true, Description: "DMI/SMBIOS hardware identity", Parse: pathParser(parseSysClassDmiId)},
+		{Name: "sys/devices/virtual/dmi/id", Kind: PatternLiteral, Pattern: "/sys/devices/virtual/dmi/id", ReadsPath: true, Description: "DMI/SMBIOS hardware identity", Parse: pathParser(parseSysClassDmiId)},
+		{Name: "sys/bus/pci/devices", Kind: PatternLiteral, Pattern: "/sys/bus/pci/devices", ReadsPath: true, Description: "PCI devices", Parse: pathParser(parseSysBusPciDevices)},
+		{Name: "sys/bus/pci/devices/address", Kind: PatternGlob, Pattern: "/sys/bus/pci/devices/*", ReadsPath: true, Description: "a PCI device", Parse: pathParser(parseSysBusPciDevice)},
+		{Name: "sys/bus/usb/devices", Kind: PatternLiteral, Pattern: "/sys/bus/usb/devices", ReadsPath: true, Description: "USB devices", Parse: pathParser(parseSysBusUsbDevices)},
+		{Name: "sys/bus/usb/devices/name", Kind: PatternGlob, Pattern: "/sys/bus/usb/devices/*", ReadsPath: true, Description: "a USB device", Parse: pathParser(parseSysBusUsbDevice)},
+		{Name: "sys/module", Kind: PatternLiteral, Pattern: "/sys/module", ReadsPath: true, Description: "kernel modules with their parameters", Parse: pathParser(parseSysModuleDir)},
+		{Name: "sys/module/name", Kind: PatternGlob, Pattern: "/sys/module/*", ReadsPath: true, Description: "a kernel module with its parameters", Parse: pathParser(parseSysModule)},
+		{Name: "sys/module/name/parameters", Kind: PatternGlob, Pattern: "/sys/module/*/parameters", ReadsPath: true, Description: "parameters of a kernel module", Parse: pathParser(parseSysModuleParameters)},
+		{Name: "sys/class/hwmon", Kind: PatternLiteral, Pattern: "/sys/class/hwmon", ReadsPath: true, Description: "hardware monitoring sensors", Parse: pathParser(parseSysClassHwmonDir)},
+		{Name: "sys/class/hwmon/hwmon", Kind: PatternGlob, Pattern: "/sys/class/hwmon/*", ReadsPath: true, Description: "sensors of a hardware monitoring device", Parse: pathParser(parseSysClassHwmon)},
+		{Name: "sys/class/thermal", Kind: PatternLiteral, Pattern: "/sys/class/thermal", ReadsPath: true, Description: "thermal zones", Parse: pathParser(parseSysClassThermalDir)},
+		{Name: "sys/class/thermal/thermal_zone", Kind: PatternGlob, Pattern: "/sys/class/thermal/thermal_zone*", ReadsPath: true, Description: "a thermal zone with its trip points", Parse: pathParser(parseSysClassThermalZone)},
+		{Name: "sys/class/power_supply", Kind: PatternLiteral, Pattern: "/sys/class/power_supply", ReadsPath: true, Description: "power supplies", Parse: pathParser(parseSysClassPowerSupplyDir)},
+		{Name: "sys/class/power_supply/name", Kind: PatternGlob, Pattern: "/sys/class/power_supply/*", ReadsPath: true, Description: "a power supply", Parse: pathParser(parseSysClassPowerSupply)},
+		{Name: "sys/kernel/mm/transparent_hugepage", Kind: PatternLiteral, Pattern: "/sys/kernel/mm/transparent_hugepage", ReadsPath: true, Description: "transparent hugepage settings", Parse: pathParser(parseSysTransparentHugepageDir)},
+		{Name: "sys/kernel/mm/transparent_hugepage/setting", Kind: PatternRegexp, Pattern: `^/sys/kernel/mm/transparent_hugepage/(?:enabled|defrag|shmem_enabled)$`, Description: "a transparent hugepage setting with its choices", Parse: mapParser(parseSysfsChoiceFile)},
+		{Name: "sys/kernel/mm/hugepages", Kind: PatternLiteral, Pattern: "/sys/kernel/mm/hugepages", ReadsPath: true, Description: "hugepage pools joined with /proc/meminfo", Parse: pathParser(parseSysKernelMmHugepagesDir)},
+		{Name: "sys/fs/cgroup", Kind: PatternRegexp, Pattern: `^/sys/fs/cgroup(?:/.*)?$`, ReadsPath: true, Description: "a control group or its file", Parse: parseCgroup},
+	} {
+		Register(p)
+	}
+}
+
+func parseProcIomem(in *Input) (interface{}, error) {
+	return createProcIomemParser(in.options().Flat)(in.Reader)
+}
+
+func parseProcPidAttrCurrent(in *Input) (interface{}, error) {
+	return createProcPidAttrCurrentParser(in.opened)(in.Reader)
+}
+
+func parseProcModules(in *Input) (interface{}, error) {
+	parser := createTableParser(noTableHeader, createTableRowParser(splitColumnsBySpace, createProcModulesColumnsParser(in.options().ModuleParameters)))
+	return parser(in.Reader)
+}
+
+func parseCgroup(in *Input) (interface{}, error) {
+	fi, err := os.Stat(in.Name)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return parseCgroupDir(in.Name)
+	}
+	return parseCgroupFile(in.Name)
+}
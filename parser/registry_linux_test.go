@@ -0,0 +1,35 @@
+//go:build linux
+
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseReaderByFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr string
+	}{
+		{format: "meminfo"},
+		{format: "/proc/meminfo"},
+		{format: "nosuch", wantErr: "nosuch is not supported"},
+		{format: "bonding", wantErr: "bonding is not supported"},
+		{format: "/sys/class/net/eth0/nosuch", wantErr: "/sys/class/net/eth0/nosuch is not supported"},
+		{format: "sys/class/net", wantErr: "sys/class/net cannot be parsed from a reader"},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.format, strings.NewReader("MemTotal: 1024 kB\n"))
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.format, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("%s: got error %v, want %q", tt.format, err, tt.wantErr)
+		}
+	}
+}
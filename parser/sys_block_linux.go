@@ -0,0 +1,214 @@
+//go:build linux
+
+package parser
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// sysBlockSectorSize is the unit of size and start in /sys/block, which is
+// always 512 bytes regardless of the logical block size of the device.
+const sysBlockSectorSize = 512
+
+// sysBlockQueueAttrs are the attributes in /sys/block/<dev>/queue to read.
+var sysBlockQueueAttrs = map[string]func(string) (interface{}, error){
+	"rotational":          readSysfsBoolAttr,
+	"scheduler":           readSysfsChoiceAttr,
+	"nr_requests":         readSysfsIntegerAttr,
+	"logical_block_size":  readSysfsIntegerAttr,
+	"physical_block_size": readSysfsIntegerAttr,
+	"discard_granularity": readSysfsIntegerAttr,
+	"read_ahead_kb":       readSysfsIntegerAttr,
+}
+
+// parseSysBlockDir reads all block devices in /sys/block.
+func parseSysBlockDir(dir string) (interface{}, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []interface{}{}
+	for _, entry := range entries {
+		dev, err := parseSysBlockDevice(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, dev)
+	}
+
+	return result, nil
+}
+
+// parseSysBlockDevice reads a block device in /sys/block/<dev> together with
+// its queue attributes and partitions.
+func parseSysBlockDevice(dir string) (interface{}, error) {
+	result, err := parseSysBlockCommon(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result["removable"], err = readSysfsBoolAttr(filepath.Join(dir, "removable"))
+	if err != nil {
+		return nil, err
+	}
+
+	// virtio block devices have serial in the device directory itself
+	for key, paths := range map[string][]string{
+		"model":  {filepath.Join(dir, "device", "model")},
+		"serial": {filepath.Join(dir, "serial"), filepath.Join(dir, "device", "serial")},
+	} {
+		result[key] = nil
+		for _, p := range paths {
+			val, err := readSysfsAttr(p)
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) || errors.Is(err, os.ErrPermission) {
+					continue
+				}
+				return nil, err
+			}
+			if val != nil {
+				result[key] = strings.TrimSpace(val.(string))
+			}
+			break
+		}
+	}
+
+	queue := make(map[string]interface{})
+	for key, read := range sysBlockQueueAttrs {
+		val, err := read(filepath.Join(dir, "queue", key))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		queue[key] = val
+	}
+	result["queue"] = queue
+
+	partitions := []interface{}{}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if _, err := os.Stat(filepath.Join(dir, entry.Name(), "partition")); err != nil {
+			continue
+		}
+
+		part, err := parseSysBlockPartition(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		partitions = append(partitions, part)
+	}
+	result["partitions"] = partitions
+
+	return result, nil
+}
+
+// parseSysBlockPartition reads a partition in /sys/block/<dev>/<part>.
+func parseSysBlockPartition(dir string) (map[string]interface{}, error) {
+	result, err := parseSysBlockCommon(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result["partition"], err = readSysfsIntegerAttr(filepath.Join(dir, "partition"))
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := readSysfsIntegerAttr(filepath.Join(dir, "start"))
+	if err != nil {
+		return nil, err
+	}
+	if start != nil {
+		start = start.(int64) * sysBlockSectorSize
+	}
+	result["start"] = start
+
+	return result, nil
+}
+
+// parseSysBlockCommon reads the attributes which both block devices and
+// partitions have.
+func parseSysBlockCommon(dir string) (map[string]interface{}, error) {
+	result := map[string]interface{}{
+		"name": filepath.Base(dir),
+	}
+
+	dev, err := readSysfsAttr(filepath.Join(dir, "dev"))
+	if err != nil {
+		return nil, err
+	}
+	result["major"], result["minor"] = nil, nil
+	if s, ok := dev.(string); ok {
+		if major, minor, found := strings.Cut(s, ":"); found {
+			if result["major"], err = strconv.ParseInt(major, 10, 64); err != nil {
+				return nil, err
+			}
+			if result["minor"], err = strconv.ParseInt(minor, 10, 64); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	size, err := readSysfsIntegerAttr(filepath.Join(dir, "size"))
+	if err != nil {
+		return nil, err
+	}
+	if size != nil {
+		size = size.(int64) * sysBlockSectorSize
+	}
+	result["size"] = size
+
+	result["ro"], err = readSysfsBoolAttr(filepath.Join(dir, "ro"))
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(dir, "stat"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	result["stat"], err = parseSysBlockStat(f)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// parseSysBlockStat parses /sys/block/<dev>/stat, which has the same columns
+// as /proc/diskstats without major, minor and name.
+func parseSysBlockStat(r io.Reader) (map[string]interface{}, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := procDiskstatsLabels[3:]
+	columns := strings.Fields(string(b))
+	if len(columns) < 11 {
+		return nil, errors.Errorf("unknown /sys/block/<dev>/stat format: found %d columns", len(columns))
+	}
+
+	result := make(map[string]interface{})
+	for i, col := range columns {
+		if i >= len(labels) {
+			break
+		}
+		val, err := strconv.ParseInt(col, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		result[labels[i]] = val
+	}
+	return result, nil
+}
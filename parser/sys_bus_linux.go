@@ -0,0 +1,202 @@
+//go:build linux
+
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// parseSysBusPciDevices reads all PCI devices in /sys/bus/pci/devices.
+func parseSysBusPciDevices(dir string) (interface{}, error) {
+	ids, err := loadHwids(pciIdsPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []interface{}{}
+	for _, entry := range entries {
+		dev, err := parsePciDevice(filepath.Join(dir, entry.Name()), ids)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, dev)
+	}
+
+	return result, nil
+}
+
+// parseSysBusPciDevice reads a PCI device in /sys/bus/pci/devices/<address>.
+func parseSysBusPciDevice(dir string) (interface{}, error) {
+	ids, err := loadHwids(pciIdsPaths)
+	if err != nil {
+		return nil, err
+	}
+	return parsePciDevice(dir, ids)
+}
+
+// parsePciDevice reads a PCI device. IDs are lower case hex strings without
+// 0x as lspci shows them, and they are resolved to names by ids if any.
+func parsePciDevice(dir string, ids *hwids) (interface{}, error) {
+	address := filepath.Base(dir)
+	result := map[string]interface{}{
+		"address": address,
+	}
+
+	// the address is <domain>:<bus>:<slot>.<function>
+	result["domain"], result["bus"], result["slot"], result["function"] = nil, nil, nil, nil
+	if parts := strings.FieldsFunc(address, func(r rune) bool { return r == ':' || r == '.' }); len(parts) == 4 {
+		for i, key := range []string{"domain", "bus", "slot", "function"} {
+			val, err := strconv.ParseInt(parts[i], 16, 64)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = val
+		}
+	}
+
+	for _, key := range []string{"vendor", "device", "subsystem_vendor", "subsystem_device", "class", "revision"} {
+		val, err := readSysfsHexIDAttr(filepath.Join(dir, key))
+		if err != nil {
+			return nil, err
+		}
+		result[key] = val
+	}
+
+	vendor, _ := result["vendor"].(string)
+	device, _ := result["device"].(string)
+	subvendor, _ := result["subsystem_vendor"].(string)
+	subdevice, _ := result["subsystem_device"].(string)
+	class, _ := result["class"].(string)
+	result["vendor_name"] = ids.vendor(vendor)
+	result["device_name"] = ids.device(vendor, device)
+	result["subsystem_name"] = ids.subsystem(vendor, device, subvendor, subdevice)
+	result["class_name"] = ids.class(class)
+	result["prog_if_name"] = ids.progIf(class)
+
+	result["driver"] = readSysfsLinkName(filepath.Join(dir, "driver"))
+
+	var err error
+	for _, key := range []string{"irq", "numa_node"} {
+		result[key], err = readSysfsIntegerAttr(filepath.Join(dir, key))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	result["enabled"], err = readSysfsBoolAttr(filepath.Join(dir, "enable"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// parseSysBusUsbDevices reads all USB devices in /sys/bus/usb/devices.
+// Interfaces of the devices (e.g. 1-1:1.0) are skipped.
+func parseSysBusUsbDevices(dir string) (interface{}, error) {
+	ids, err := loadHwids(usbIdsPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []interface{}{}
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ":") {
+			continue
+		}
+
+		dev, err := parseUsbDevice(filepath.Join(dir, entry.Name()), ids)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, dev)
+	}
+
+	return result, nil
+}
+
+// parseSysBusUsbDevice reads a USB device in /sys/bus/usb/devices/<name>.
+func parseSysBusUsbDevice(dir string) (interface{}, error) {
+	ids, err := loadHwids(usbIdsPaths)
+	if err != nil {
+		return nil, err
+	}
+	return parseUsbDevice(dir, ids)
+}
+
+// parseUsbDevice reads a USB device. speed is in Mbps as it is in sysfs, and
+// idVendor and idProduct are resolved to names by ids if any.
+func parseUsbDevice(dir string, ids *hwids) (interface{}, error) {
+	result := map[string]interface{}{
+		"name": filepath.Base(dir),
+	}
+
+	var err error
+	for _, key := range []string{"busnum", "devnum", "maxchild"} {
+		result[key], err = readSysfsIntegerAttr(filepath.Join(dir, key))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	for _, key := range []string{"idVendor", "idProduct", "bDeviceClass", "bcdDevice", "manufacturer", "product", "serial", "version"} {
+		val, err := readSysfsAttr(filepath.Join(dir, key))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if s, ok := val.(string); ok {
+			val = strings.TrimSpace(s)
+		}
+		result[key] = val
+	}
+
+	speed, err := readSysfsAttr(filepath.Join(dir, "speed"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if s, ok := speed.(string); ok {
+		// low speed devices are 1.5 Mbps
+		speed = parseNumberOrString(s)
+	}
+	result["speed"] = speed
+
+	vendor, _ := result["idVendor"].(string)
+	product, _ := result["idProduct"].(string)
+	result["vendor_name"] = ids.vendor(vendor)
+	result["product_name"] = ids.device(vendor, product)
+
+	result["driver"] = readSysfsLinkName(filepath.Join(dir, "driver"))
+
+	return result, nil
+}
+
+// readSysfsHexIDAttr reads an attribute such as vendor ("0x8086") and returns
+// it without 0x.
+func readSysfsHexIDAttr(path string) (interface{}, error) {
+	val, err := readSysfsAttr(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if s, ok := val.(string); ok {
+		return strings.TrimPrefix(strings.TrimSpace(s), "0x"), nil
+	}
+	return val, nil
+}
@@ -0,0 +1,151 @@
+//go:build linux
+
+package parser
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePciDevice(t *testing.T) {
+	root := writeSysfsFixture(t, map[string]string{
+		"0000:00:14.0/vendor":           "0x8086\n",
+		"0000:00:14.0/device":           "0x1533\n",
+		"0000:00:14.0/subsystem_vendor": "0x15d9\n",
+		"0000:00:14.0/subsystem_device": "0x1533\n",
+		"0000:00:14.0/class":            "0x0c0330\n",
+		"0000:00:14.0/revision":         "0x03\n",
+		"0000:00:14.0/irq":              "128\n",
+		"0000:00:14.0/numa_node":        "-1\n",
+		"0000:00:14.0/enable":           "1\n",
+		"0000:00:14.0/driver":           "->../../../bus/pci/drivers/xhci_hcd",
+	})
+	ids, err := parseHwids(strings.NewReader(testPciIds))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := parsePciDevice(filepath.Join(root, "0000:00:14.0"), ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"address":          "0000:00:14.0",
+		"domain":           int64(0),
+		"bus":              int64(0),
+		"slot":             int64(0x14),
+		"function":         int64(0),
+		"vendor":           "8086",
+		"device":           "1533",
+		"subsystem_vendor": "15d9",
+		"subsystem_device": "1533",
+		"class":            "0c0330",
+		"revision":         "03",
+		"vendor_name":      "Intel Corporation",
+		"device_name":      "I210 Gigabit Network Connection",
+		"subsystem_name":   "I210 Gigabit Network Connection",
+		"class_name":       "USB controller",
+		"prog_if_name":     "XHCI",
+		"driver":           "xhci_hcd",
+		"irq":              int64(128),
+		"numa_node":        int64(-1),
+		"enabled":          true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestParsePciDeviceWithoutIds(t *testing.T) {
+	root := writeSysfsFixture(t, map[string]string{
+		"0000:00:02.0/vendor": "0x10de\n",
+		"0000:00:02.0/device": "0x1234\n",
+		"0000:00:02.0/class":  "0x030000\n",
+	})
+
+	got, err := parsePciDevice(filepath.Join(root, "0000:00:02.0"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := got.(map[string]interface{})
+	for _, key := range []string{"vendor_name", "device_name", "class_name", "prog_if_name", "driver", "irq", "enabled"} {
+		if m[key] != nil {
+			t.Errorf("%s: got %#v, want nil", key, m[key])
+		}
+	}
+	if m["slot"] != int64(2) {
+		t.Errorf("slot: got %#v, want 2", m["slot"])
+	}
+}
+
+func TestParseUsbDevice(t *testing.T) {
+	root := writeSysfsFixture(t, map[string]string{
+		"usb2/busnum":       "2\n",
+		"usb2/devnum":       "1\n",
+		"usb2/maxchild":     "4\n",
+		"usb2/idVendor":     "1d6b\n",
+		"usb2/idProduct":    "0003\n",
+		"usb2/bDeviceClass": "09\n",
+		"usb2/bcdDevice":    "0515\n",
+		"usb2/manufacturer": "Linux 5.15.0 xhci-hcd\n",
+		"usb2/product":      "xHCI Host Controller\n",
+		"usb2/serial":       "0000:00:14.0\n",
+		"usb2/version":      " 3.00\n",
+		"usb2/speed":        "5000\n",
+		"usb2/driver":       "->../../../bus/usb/drivers/usb",
+		"1-1/busnum":        "1\n",
+		"1-1/devnum":        "2\n",
+		"1-1/idVendor":      "046d\n",
+		"1-1/idProduct":     "c52b\n",
+		"1-1/speed":         "1.5\n",
+	})
+	ids, err := parseHwids(strings.NewReader(testUsbIds))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := parseUsbDevice(filepath.Join(root, "usb2"), ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"name":         "usb2",
+		"busnum":       int64(2),
+		"devnum":       int64(1),
+		"maxchild":     int64(4),
+		"idVendor":     "1d6b",
+		"idProduct":    "0003",
+		"bDeviceClass": "09",
+		"bcdDevice":    "0515",
+		"manufacturer": "Linux 5.15.0 xhci-hcd",
+		"product":      "xHCI Host Controller",
+		"serial":       "0000:00:14.0",
+		"version":      "3.00",
+		"speed":        int64(5000),
+		"vendor_name":  "Linux Foundation",
+		"product_name": "3.0 root hub",
+		"driver":       "usb",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+
+	got, err = parseUsbDevice(filepath.Join(root, "1-1"), ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := got.(map[string]interface{})
+	if m["speed"] != 1.5 {
+		t.Errorf("speed: got %#v, want 1.5", m["speed"])
+	}
+	if m["vendor_name"] != nil || m["product_name"] != nil {
+		t.Errorf("unknown IDs should not be resolved: got %#v, %#v", m["vendor_name"], m["product_name"])
+	}
+	if m["maxchild"] != nil || m["manufacturer"] != nil {
+		t.Errorf("missing attributes should be null: got %#v, %#v", m["maxchild"], m["manufacturer"])
+	}
+}
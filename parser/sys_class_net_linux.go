@@ -0,0 +1,102 @@
+//go:build linux
+
+package parser
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// parseSysClassNetDir reads all network interfaces in /sys/class/net.
+func parseSysClassNetDir(dir string) (interface{}, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []interface{}{}
+	for _, entry := range entries {
+		// skip files such as bonding_masters
+		if fi, err := os.Stat(filepath.Join(dir, entry.Name())); err != nil || !fi.IsDir() {
+			continue
+		}
+
+		iface, err := parseSysClassNetInterface(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, iface)
+	}
+
+	return result, nil
+}
+
+// parseSysClassNetInterface reads the attributes, queues and statistics of a
+// network interface in /sys/class/net/<interface>.
+func parseSysClassNetInterface(dir string) (interface{}, error) {
+	result, err := readSysfsAttrs(dir, "uevent")
+	if err != nil {
+		return nil, err
+	}
+	result["name"] = filepath.Base(dir)
+
+	// flags and dev_id are printed in hex
+	for _, key := range []string{"flags", "dev_id"} {
+		s, ok := result[key].(string)
+		if !ok {
+			continue
+		}
+		if val, err := strconv.ParseInt(strings.TrimPrefix(s, "0x"), 16, 64); err == nil {
+			result[key] = val
+		}
+	}
+
+	result["driver"] = readSysfsLinkName(filepath.Join(dir, "device", "driver"))
+
+	queues := map[string]interface{}{
+		"rx": []interface{}{},
+		"tx": []interface{}{},
+	}
+	for _, kind := range []string{"rx", "tx"} {
+		names, err := listSysfsDir(filepath.Join(dir, "queues"), kind+"-*")
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			queues[kind] = append(queues[kind].([]interface{}), name)
+		}
+	}
+	result["queues"] = queues
+
+	statistics, err := readSysfsAttrs(filepath.Join(dir, "statistics"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if statistics != nil {
+		result["statistics"] = statistics
+	}
+
+	return result, nil
+}
+
+func parseSysClassNetStatistics(dir string) (interface{}, error) {
+	return readSysfsAttrs(dir)
+}
+
+// parseSysClassNetBondingMasters parses /sys/class/net/bonding_masters, which
+// lists the bonding interfaces separated by spaces.
+func parseSysClassNetBondingMasters(r io.Reader) ([]interface{}, error) {
+	s, err := readTrimmed(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []interface{}{}
+	for _, name := range strings.Fields(s) {
+		result = append(result, name)
+	}
+	return result, nil
+}
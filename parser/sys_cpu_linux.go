@@ -0,0 +1,246 @@
+//go:build linux
+
+package parser
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// sysCpuRangeListAttrs are the attributes in /sys/devices/system/cpu which
+// hold a list of CPUs in range syntax such as "0-3,8".
+var sysCpuRangeListAttrs = []string{"online", "offline", "possible", "present", "isolated"}
+
+// parseSysCpuDir reads /sys/devices/system/cpu, i.e. the lists of CPUs,
+// the details of each CPU and the vulnerabilities.
+func parseSysCpuDir(dir string) (interface{}, error) {
+	result := make(map[string]interface{})
+	for _, key := range sysCpuRangeListAttrs {
+		val, err := readSysfsRangeListAttr(filepath.Join(dir, key))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		result[key] = val
+	}
+
+	var err error
+	result["kernel_max"], err = readSysfsIntegerAttr(filepath.Join(dir, "kernel_max"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	smt, err := readSysfsAttrs(filepath.Join(dir, "smt"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	result["smt"] = smt
+
+	result["vulnerabilities"], err = parseSysCpuVulnerabilities(filepath.Join(dir, "vulnerabilities"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	names, err := listSysfsDir(dir, "cpu[0-9]*")
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return sysCpuNumber(names[i]) < sysCpuNumber(names[j])
+	})
+
+	cpus := []interface{}{}
+	for _, name := range names {
+		cpu, err := parseSysCpu(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		cpus = append(cpus, cpu)
+	}
+	result["cpus"] = cpus
+
+	return result, nil
+}
+
+// parseSysCpu reads the topology, cpufreq and cpuidle of a CPU in
+// /sys/devices/system/cpu/cpu<N>.
+func parseSysCpu(dir string) (interface{}, error) {
+	result := map[string]interface{}{
+		"cpu": sysCpuNumber(filepath.Base(dir)),
+	}
+
+	// cpu0 usually cannot be offlined and has no online attribute
+	online, err := readSysfsBoolAttr(filepath.Join(dir, "online"))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		online = true
+	}
+	result["online"] = online
+
+	result["topology"], err = parseSysCpuTopology(filepath.Join(dir, "topology"))
+	if err != nil {
+		return nil, err
+	}
+
+	result["cpufreq"], err = parseSysCpufreq(filepath.Join(dir, "cpufreq"))
+	if err != nil {
+		return nil, err
+	}
+
+	result["cpuidle"], err = parseSysCpuidle(filepath.Join(dir, "cpuidle"))
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// parseSysCpuTopology reads the topology directory of a CPU. Offline CPUs
+// have no topology, and nil is returned for them.
+func parseSysCpuTopology(dir string) (interface{}, error) {
+	attrs, err := readSysfsAttrs(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	for key, val := range attrs {
+		switch {
+		case strings.HasSuffix(key, "_list"):
+			s, ok := val.(string)
+			if !ok {
+				// a single CPU such as "0" has been typed as an integer
+				result[strings.TrimSuffix(key, "_list")] = []interface{}{val}
+				continue
+			}
+			list, err := parseRangeList(s)
+			if err != nil {
+				return nil, err
+			}
+			result[strings.TrimSuffix(key, "_list")] = list
+
+		case strings.HasSuffix(key, "_id"):
+			result[key] = val
+		}
+	}
+
+	return result, nil
+}
+
+// parseSysCpufreq reads the cpufreq directory of a CPU. Frequencies are in
+// kHz as they are in sysfs. nil is returned if there is no cpufreq driver.
+func parseSysCpufreq(dir string) (interface{}, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, nil
+	}
+
+	result := make(map[string]interface{})
+	for key, attr := range map[string]string{
+		"driver":           "scaling_driver",
+		"governor":         "scaling_governor",
+		"cur_freq":         "scaling_cur_freq",
+		"min_freq":         "scaling_min_freq",
+		"max_freq":         "scaling_max_freq",
+		"cpuinfo_min_freq": "cpuinfo_min_freq",
+		"cpuinfo_max_freq": "cpuinfo_max_freq",
+	} {
+		val, err := readSysfsTypedAttr(filepath.Join(dir, attr))
+		if err != nil && !errors.Is(err, os.ErrNotExist) && !errors.Is(err, os.ErrPermission) {
+			return nil, err
+		}
+		result[key] = val
+	}
+
+	result["available_governors"] = []interface{}{}
+	governors, err := readSysfsAttr(filepath.Join(dir, "scaling_available_governors"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if s, ok := governors.(string); ok {
+		for _, governor := range strings.Fields(s) {
+			result["available_governors"] = append(result["available_governors"].([]interface{}), governor)
+		}
+	}
+
+	return result, nil
+}
+
+// parseSysCpuidle reads the idle states of a CPU in cpuidle/state<N>. time and
+// residency are in microseconds.
+func parseSysCpuidle(dir string) (interface{}, error) {
+	names, err := listSysfsDir(dir, "state[0-9]*")
+	if err != nil {
+		return nil, err
+	}
+
+	result := []interface{}{}
+	for _, name := range names {
+		state, err := readSysfsAttrs(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		state["state"], err = strconv.ParseInt(strings.TrimPrefix(name, "state"), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if disable, ok := state["disable"].(int64); ok {
+			state["disable"] = disable != 0
+		}
+		result = append(result, state)
+	}
+
+	return result, nil
+}
+
+// parseSysCpuVulnerabilities reads /sys/devices/system/cpu/vulnerabilities
+// into an object of mitigation strings.
+func parseSysCpuVulnerabilities(dir string) (interface{}, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	for _, entry := range entries {
+		val, err := readSysfsAttr(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		result[entry.Name()] = val
+	}
+
+	return result, nil
+}
+
+func parseSysCpuRangeList(r io.Reader) ([]interface{}, error) {
+	s, err := readTrimmed(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseRangeList(s)
+}
+
+// readSysfsRangeListAttr reads a sysfs attribute in range syntax such as
+// "0-3,8".
+func readSysfsRangeListAttr(path string) (interface{}, error) {
+	val, err := readSysfsAttr(path)
+	if err != nil || val == nil {
+		return val, err
+	}
+	return parseRangeList(val.(string))
+}
+
+func sysCpuNumber(name string) int64 {
+	n, _ := strconv.ParseInt(strings.TrimPrefix(name, "cpu"), 10, 64)
+	return n
+}
@@ -0,0 +1,96 @@
+//go:build linux
+
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// chassisTypeNames are the names of the chassis types defined in the SMBIOS
+// specification (System Enclosure or Chassis, Type 3).
+var chassisTypeNames = map[int64]string{
+	1:  "Other",
+	2:  "Unknown",
+	3:  "Desktop",
+	4:  "Low Profile Desktop",
+	5:  "Pizza Box",
+	6:  "Mini Tower",
+	7:  "Tower",
+	8:  "Portable",
+	9:  "Laptop",
+	10: "Notebook",
+	11: "Hand Held",
+	12: "Docking Station",
+	13: "All in One",
+	14: "Sub Notebook",
+	15: "Space-saving",
+	16: "Lunch Box",
+	17: "Main Server Chassis",
+	18: "Expansion Chassis",
+	19: "SubChassis",
+	20: "Bus Expansion Chassis",
+	21: "Peripheral Chassis",
+	22: "RAID Chassis",
+	23: "Rack Mount Chassis",
+	24: "Sealed-case PC",
+	25: "Multi-system chassis",
+	26: "Compact PCI",
+	27: "Advanced TCA",
+	28: "Blade",
+	29: "Blade Enclosure",
+	30: "Tablet",
+	31: "Convertible",
+	32: "Detachable",
+	33: "IoT Gateway",
+	34: "Embedded PC",
+	35: "Mini PC",
+	36: "Stick PC",
+}
+
+// parseSysClassDmiId reads /sys/class/dmi/id. Every attribute is kept as a
+// string. Attributes readable only by root (e.g. product_serial) are null and
+// listed in permission_denied.
+func parseSysClassDmiId(dir string) (interface{}, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	permissionDenied := []interface{}{}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || entry.Name() == "uevent" {
+			continue
+		}
+
+		val, err := readSysfsAttr(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			if !errors.Is(err, os.ErrPermission) {
+				return nil, err
+			}
+			permissionDenied = append(permissionDenied, entry.Name())
+		}
+		if s, ok := val.(string); ok {
+			val = strings.TrimSpace(s)
+		}
+		result[entry.Name()] = val
+	}
+	result["permission_denied"] = permissionDenied
+
+	result["chassis_type_name"] = nil
+	if s, ok := result["chassis_type"].(string); ok {
+		// the most significant bit indicates the presence of a chassis lock
+		if chassisType, err := strconv.ParseInt(s, 10, 64); err == nil {
+			if name, ok := chassisTypeNames[chassisType&0x7f]; ok {
+				result["chassis_type_name"] = name
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,146 @@
+//go:build linux
+
+package parser
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// meminfoHugePagesKeys maps the HugePages_* keys in /proc/meminfo to the
+// attribute names in /sys/kernel/mm/hugepages/hugepages-<size>kB.
+var meminfoHugePagesKeys = map[string]string{
+	"HugePages_Total": "nr_hugepages",
+	"HugePages_Free":  "free_hugepages",
+	"HugePages_Rsvd":  "resv_hugepages",
+	"HugePages_Surp":  "surplus_hugepages",
+}
+
+// parseSysTransparentHugepageDir reads /sys/kernel/mm/transparent_hugepage.
+// Attributes such as enabled and defrag are parsed by parseSysfsChoice, and
+// the per-size settings in hugepages-<size>kB are read into hugepages keyed
+// by size (e.g. "2048kB").
+func parseSysTransparentHugepageDir(dir string) (interface{}, error) {
+	result, err := readSysfsChoiceAttrs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	khugepaged, err := readSysfsChoiceAttrs(filepath.Join(dir, "khugepaged"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	result["khugepaged"] = khugepaged
+
+	hugepages := make(map[string]interface{})
+	paths, err := filepath.Glob(filepath.Join(dir, "hugepages-*kB"))
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range paths {
+		size, err := parseSysHugepagesSize(filepath.Base(p))
+		if err != nil {
+			return nil, err
+		}
+
+		attrs, err := readSysfsChoiceAttrs(p)
+		if err != nil {
+			return nil, err
+		}
+		attrs["size_kb"] = size
+		hugepages[strings.TrimPrefix(filepath.Base(p), "hugepages-")] = attrs
+	}
+	result["hugepages"] = hugepages
+
+	return result, nil
+}
+
+// parseSysfsChoiceFile parses a file such as
+// /sys/kernel/mm/transparent_hugepage/enabled by parseSysfsChoice.
+func parseSysfsChoiceFile(r io.Reader) (map[string]interface{}, error) {
+	s, err := readTrimmed(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseSysfsChoice(s), nil
+}
+
+// parseSysKernelMmHugepagesDir reads /sys/kernel/mm/hugepages. The entry of
+// the default hugepage size is marked as default and joined with the
+// HugePages_* values in /proc/meminfo, which are only for the default size.
+func parseSysKernelMmHugepagesDir(dir string) (interface{}, error) {
+	result, err := parseSysHugepagesDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	meminfo, err := readProcMeminfoHugePages()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range result {
+		hugepages := entry.(map[string]interface{})
+		hugepages["default"] = false
+
+		defaultSize, ok := meminfo["Hugepagesize"].(map[string]interface{})
+		if !ok || defaultSize["value"] != hugepages["size_kb"] {
+			continue
+		}
+
+		hugepages["default"] = true
+		joined := make(map[string]interface{})
+		for key, attr := range meminfoHugePagesKeys {
+			if v, ok := meminfo[key].(map[string]interface{}); ok {
+				joined[attr] = v["value"]
+			}
+		}
+		hugepages["meminfo"] = joined
+	}
+
+	return result, nil
+}
+
+// readProcMeminfoHugePages reads the values concerning hugepages from
+// /proc/meminfo. The keys are kept as they are in /proc/meminfo.
+func readProcMeminfoHugePages() (map[string]interface{}, error) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	meminfo, err := createMapParser(createLineParser(splitLineByColon, parseProcMeminfoValue))(f)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	for key, val := range meminfo {
+		if _, ok := meminfoHugePagesKeys[key]; ok || key == "Hugepagesize" {
+			result[key] = val
+		}
+	}
+	return result, nil
+}
+
+// readSysfsChoiceAttrs reads attributes in dir by readSysfsAttrs and parses
+// the ones with a bracketed active choice by parseSysfsChoice.
+func readSysfsChoiceAttrs(dir string) (map[string]interface{}, error) {
+	result, err := readSysfsAttrs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, val := range result {
+		if s, ok := val.(string); ok && strings.Contains(s, "[") {
+			result[key] = parseSysfsChoice(s)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,96 @@
+//go:build linux
+
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// parseSysModuleDir reads all modules in /sys/module, including the ones
+// built into the kernel which have only parameters.
+func parseSysModuleDir(dir string) (interface{}, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []interface{}{}
+	for _, entry := range entries {
+		module, err := parseSysModule(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, module)
+	}
+
+	return result, nil
+}
+
+// parseSysModule reads a module in /sys/module/<name>. The attributes which
+// built-in modules do not have (e.g. refcnt) are null.
+func parseSysModule(dir string) (interface{}, error) {
+	result := map[string]interface{}{
+		"name": filepath.Base(dir),
+	}
+
+	for _, key := range []string{"version", "srcversion", "initstate", "taint"} {
+		val, err := readSysfsAttr(filepath.Join(dir, key))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		result[key] = val
+	}
+
+	for _, key := range []string{"refcnt", "coresize", "initsize"} {
+		val, err := readSysfsIntegerAttr(filepath.Join(dir, key))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		result[key] = val
+	}
+
+	holders, err := listSysfsDir(filepath.Join(dir, "holders"), "*")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(holders)
+	result["holders"] = []interface{}{}
+	for _, holder := range holders {
+		result["holders"] = append(result["holders"].([]interface{}), holder)
+	}
+
+	result["parameters"], err = parseSysModuleParameters(filepath.Join(dir, "parameters"))
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// parseSysModuleParameters reads /sys/module/<name>/parameters. Boolean
+// parameters shown as Y or N are typed as booleans and integers as integers.
+// An empty object is returned for modules without parameters.
+func parseSysModuleParameters(dir string) (interface{}, error) {
+	result, err := readSysfsAttrs(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]interface{}{}, nil
+		}
+		return nil, err
+	}
+
+	for key, val := range result {
+		switch val {
+		case "Y":
+			result[key] = true
+		case "N":
+			result[key] = false
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,226 @@
+//go:build linux
+
+package parser
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// sysNodeRangeListAttrs are the attributes in /sys/devices/system/node which
+// hold a list of nodes in range syntax such as "0-1".
+var sysNodeRangeListAttrs = []string{"online", "possible", "has_cpu", "has_memory", "has_normal_memory"}
+
+var reSysNodeMeminfoPrefix = regexp.MustCompile(`^Node\s+\d+\s+`)
+
+// parseSysNodeDir reads /sys/devices/system/node, i.e. the lists of nodes,
+// the details of each node and the distance matrix between them.
+func parseSysNodeDir(dir string) (interface{}, error) {
+	result := make(map[string]interface{})
+	for _, key := range sysNodeRangeListAttrs {
+		val, err := readSysfsRangeListAttr(filepath.Join(dir, key))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		result[key] = val
+	}
+
+	names, err := listSysfsDir(dir, "node[0-9]*")
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return sysNodeNumber(names[i]) < sysNodeNumber(names[j])
+	})
+
+	nodes := []interface{}{}
+	distance := []interface{}{}
+	for _, name := range names {
+		node, err := parseSysNode(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+		distance = append(distance, node.(map[string]interface{})["distance"])
+	}
+	result["nodes"] = nodes
+	result["distance"] = distance
+
+	return result, nil
+}
+
+// parseSysNode reads a NUMA node in /sys/devices/system/node/node<N>.
+func parseSysNode(dir string) (interface{}, error) {
+	result := map[string]interface{}{
+		"node": sysNodeNumber(filepath.Base(dir)),
+	}
+
+	var err error
+	result["cpulist"], err = readSysfsRangeListAttr(filepath.Join(dir, "cpulist"))
+	if err != nil {
+		return nil, err
+	}
+
+	for key, parser := range map[string]func(io.Reader) (interface{}, error){
+		"distance": parseSysNodeDistance,
+		"meminfo": func(r io.Reader) (interface{}, error) {
+			return createMapParser(createLineParser(splitSysNodeMeminfoLine, parseProcMeminfoValue))(r)
+		},
+		"numastat": func(r io.Reader) (interface{}, error) {
+			return createMapParser(createLineParser(splitLineBySpace, parseProcVmstatValue))(r)
+		},
+	} {
+		f, err := os.Open(filepath.Join(dir, key))
+		if err != nil {
+			return nil, err
+		}
+		result[key], err = parser(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result["hugepages"] = nil
+	hugepages, err := parseSysHugepagesDir(filepath.Join(dir, "hugepages"))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	} else {
+		result["hugepages"] = hugepages
+	}
+
+	return result, nil
+}
+
+// parseSysNodeDistance parses node<N>/distance, i.e. the distances from the
+// node to each node.
+func parseSysNodeDistance(r io.Reader) (interface{}, error) {
+	s, err := readTrimmed(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []interface{}{}
+	for _, field := range strings.Fields(s) {
+		val, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, val)
+	}
+	return result, nil
+}
+
+func parseSysNodeDistanceArray(r io.Reader) ([]interface{}, error) {
+	val, err := parseSysNodeDistance(r)
+	if err != nil {
+		return nil, err
+	}
+	return val.([]interface{}), nil
+}
+
+// splitSysNodeMeminfoLine strips the "Node N" prefix from a line in
+// node<N>/meminfo so that it can be parsed in the same way as /proc/meminfo.
+func splitSysNodeMeminfoLine(line string) (string, string, error) {
+	return splitLineByColon(reSysNodeMeminfoPrefix.ReplaceAllString(line, ""))
+}
+
+// parseSysHugepagesDir reads hugepages-<size>kB directories in dir such as
+// /sys/devices/system/node/node<N>/hugepages into an object keyed by size
+// (e.g. "2048kB").
+func parseSysHugepagesDir(dir string) (map[string]interface{}, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	for _, entry := range entries {
+		size, err := parseSysHugepagesSize(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		pages, err := readSysfsAttrs(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		pages["size_kb"] = size
+		result[strings.TrimPrefix(entry.Name(), "hugepages-")] = pages
+	}
+
+	return result, nil
+}
+
+// parseSysHugepagesSize returns the size in kB of a directory named
+// hugepages-<size>kB.
+func parseSysHugepagesSize(name string) (int64, error) {
+	sizeStr := strings.TrimSuffix(strings.TrimPrefix(name, "hugepages-"), "kB")
+	return strconv.ParseInt(sizeStr, 10, 64)
+}
+
+// parseProcPidNumaMaps parses /proc/<pid>/numa_maps. Each line is the start
+// address of a mapping, its memory policy and key=value pairs, where N<node>
+// pairs are the number of pages on each node.
+func parseProcPidNumaMaps(r io.Reader) ([]interface{}, error) {
+	result := []interface{}{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+
+		mapping := map[string]interface{}{
+			"address": fields[0],
+			"policy":  fields[1],
+		}
+		nodes := make(map[string]interface{})
+		for _, field := range fields[2:] {
+			key, valStr, found := strings.Cut(field, "=")
+			if !found {
+				// flags such as heap, stack and huge
+				mapping[key] = true
+				continue
+			}
+
+			if key == "file" {
+				mapping[key] = valStr
+				continue
+			}
+
+			val, err := strconv.ParseInt(valStr, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			if strings.HasPrefix(key, "N") && isLikelyInteger(key[1:]) {
+				nodes[key[1:]] = val
+			} else {
+				mapping[key] = val
+			}
+		}
+		mapping["nodes"] = nodes
+
+		result = append(result, mapping)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func sysNodeNumber(name string) int64 {
+	n, _ := strconv.ParseInt(strings.TrimPrefix(name, "node"), 10, 64)
+	return n
+}
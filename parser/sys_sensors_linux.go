@@ -0,0 +1,255 @@
+//go:build linux
+
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// hwmonSensorTypes are the types of hwmon sensors to read, with the divisor
+// to scale their values into °C, RPM and V respectively.
+var hwmonSensorTypes = []struct {
+	name    string
+	divisor float64
+}{
+	{"temp", 1000}, // millidegree Celsius
+	{"fan", 1},     // RPM
+	{"in", 1000},   // millivolt
+}
+
+// hwmonSensorThresholds are the attributes read for each sensor in addition
+// to its input and label.
+var hwmonSensorThresholds = []string{"min", "max", "crit", "lcrit"}
+
+// powerSupplyMicroUnitAttrs are the attributes of power supplies which are in
+// micro units (µWh, µAh, µV, µA and µW) in sysfs. Others sharing the prefixes
+// are not (e.g. charge_control_end_threshold is in percent).
+var powerSupplyMicroUnitAttrs = []string{
+	"energy_now", "energy_avg", "energy_full", "energy_full_design", "energy_empty", "energy_empty_design",
+	"charge_now", "charge_avg", "charge_counter", "charge_full", "charge_full_design", "charge_empty", "charge_empty_design",
+	"charge_control_limit", "charge_control_limit_max", "charge_term_current", "precharge_current",
+	"constant_charge_current", "constant_charge_current_max", "constant_charge_voltage", "constant_charge_voltage_max",
+	"voltage_now", "voltage_avg", "voltage_ocv", "voltage_boot", "voltage_min", "voltage_max", "voltage_min_design", "voltage_max_design",
+	"current_now", "current_avg", "current_boot", "current_max",
+	"power_now", "power_avg",
+	"input_current_limit", "input_voltage_limit", "input_power_limit",
+}
+
+var reThermalTripPoint = regexp.MustCompile(`^trip_point_(\d+)_type$`)
+
+// parseSysClassDir reads all devices in a /sys/class/<class> directory by
+// parser.
+func parseSysClassDir(dir string, parser func(string) (interface{}, error)) (interface{}, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []interface{}{}
+	for _, entry := range entries {
+		dev, err := parser(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, dev)
+	}
+
+	return result, nil
+}
+
+func parseSysClassHwmonDir(dir string) (interface{}, error) {
+	return parseSysClassDir(dir, parseSysClassHwmon)
+}
+
+// parseSysClassHwmon reads a hwmon device in /sys/class/hwmon/hwmon<N>.
+func parseSysClassHwmon(dir string) (interface{}, error) {
+	name, err := readSysfsAttr(filepath.Join(dir, "name"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"hwmon": filepath.Base(dir),
+		"name":  name,
+	}
+
+	for _, sensorType := range hwmonSensorTypes {
+		inputs, err := filepath.Glob(filepath.Join(dir, sensorType.name+"[0-9]*_input"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(inputs, func(i, j int) bool {
+			return sysfsIndex(inputs[i], sensorType.name) < sysfsIndex(inputs[j], sensorType.name)
+		})
+
+		sensors := []interface{}{}
+		for _, input := range inputs {
+			prefix := strings.TrimSuffix(input, "_input")
+			sensor := map[string]interface{}{
+				"sensor": filepath.Base(prefix),
+			}
+
+			sensor["label"], err = readSysfsAttr(prefix + "_label")
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+
+			val, err := readSysfsIntegerAttr(input)
+			if err != nil {
+				return nil, err
+			}
+			sensor["input"] = scaleSysfsValue(val, sensorType.divisor)
+
+			for _, threshold := range hwmonSensorThresholds {
+				val, err := readSysfsIntegerAttr(prefix + "_" + threshold)
+				if err != nil {
+					if errors.Is(err, os.ErrNotExist) {
+						continue
+					}
+					return nil, err
+				}
+				sensor[threshold] = scaleSysfsValue(val, sensorType.divisor)
+			}
+
+			sensors = append(sensors, sensor)
+		}
+		result[sensorType.name] = sensors
+	}
+
+	return result, nil
+}
+
+func parseSysClassThermalDir(dir string) (interface{}, error) {
+	zones, err := filepath.Glob(filepath.Join(dir, "thermal_zone[0-9]*"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(zones, func(i, j int) bool {
+		return sysfsIndex(zones[i], "thermal_zone") < sysfsIndex(zones[j], "thermal_zone")
+	})
+
+	result := []interface{}{}
+	for _, zone := range zones {
+		val, err := parseSysClassThermalZone(zone)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, val)
+	}
+
+	return result, nil
+}
+
+// parseSysClassThermalZone reads a thermal zone in
+// /sys/class/thermal/thermal_zone<N>. Temperatures are scaled into °C.
+func parseSysClassThermalZone(dir string) (interface{}, error) {
+	result := map[string]interface{}{
+		"zone": filepath.Base(dir),
+	}
+
+	for _, key := range []string{"type", "policy", "mode"} {
+		val, err := readSysfsAttr(filepath.Join(dir, key))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		result[key] = val
+	}
+
+	temp, err := readSysfsIntegerAttr(filepath.Join(dir, "temp"))
+	if err != nil {
+		return nil, err
+	}
+	result["temp"] = scaleSysfsValue(temp, 1000)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	tripPoints := []interface{}{}
+	for _, entry := range entries {
+		submatch := reThermalTripPoint.FindStringSubmatch(entry.Name())
+		if len(submatch) != 2 {
+			continue
+		}
+
+		trip, err := strconv.ParseInt(submatch[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		tripPoint := map[string]interface{}{
+			"trip": trip,
+		}
+
+		prefix := filepath.Join(dir, "trip_point_"+submatch[1])
+		tripPoint["type"], err = readSysfsAttr(prefix + "_type")
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range []string{"temp", "hyst"} {
+			val, err := readSysfsIntegerAttr(prefix + "_" + key)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+			tripPoint[key] = scaleSysfsValue(val, 1000)
+		}
+
+		tripPoints = append(tripPoints, tripPoint)
+	}
+	sort.Slice(tripPoints, func(i, j int) bool {
+		return tripPoints[i].(map[string]interface{})["trip"].(int64) < tripPoints[j].(map[string]interface{})["trip"].(int64)
+	})
+	result["trip_points"] = tripPoints
+
+	return result, nil
+}
+
+func parseSysClassPowerSupplyDir(dir string) (interface{}, error) {
+	return parseSysClassDir(dir, parseSysClassPowerSupply)
+}
+
+// parseSysClassPowerSupply reads a power supply in
+// /sys/class/power_supply/<name>. Energy, charge, voltage, current and power
+// are scaled into Wh, Ah, V, A and W respectively.
+func parseSysClassPowerSupply(dir string) (interface{}, error) {
+	result, err := readSysfsAttrs(dir, "uevent")
+	if err != nil {
+		return nil, err
+	}
+	result["name"] = filepath.Base(dir)
+
+	for _, key := range powerSupplyMicroUnitAttrs {
+		if val, ok := result[key]; ok {
+			result[key] = scaleSysfsValue(val, 1000000)
+		}
+	}
+
+	return result, nil
+}
+
+// scaleSysfsValue divides an integer value by divisor. Values other than
+// integers (e.g. nil for unavailable attributes) are returned as they are.
+func scaleSysfsValue(val interface{}, divisor float64) interface{} {
+	n, ok := val.(int64)
+	if !ok {
+		return val
+	}
+	if divisor == 1 {
+		return n
+	}
+	return float64(n) / divisor
+}
+
+// sysfsIndex returns N of an entry named such as <prefix>N or <prefix>N_input.
+func sysfsIndex(path, prefix string) int64 {
+	name := strings.TrimPrefix(filepath.Base(path), prefix)
+	name, _, _ = strings.Cut(name, "_")
+	n, _ := strconv.ParseInt(name, 10, 64)
+	return n
+}
@@ -0,0 +1,115 @@
+//go:build linux
+
+package parser
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseSysClassHwmon(t *testing.T) {
+	root := writeSysfsFixture(t, map[string]string{
+		"hwmon0/name":         "coretemp\n",
+		"hwmon0/temp1_input":  "45000\n",
+		"hwmon0/temp1_label":  "Package id 0\n",
+		"hwmon0/temp1_max":    "100000\n",
+		"hwmon0/temp1_crit":   "105000\n",
+		"hwmon0/temp10_input": "30000\n",
+		"hwmon0/temp2_input":  "40500\n",
+		"hwmon0/fan1_input":   "1200\n",
+		"hwmon0/in0_input":    "1104\n",
+		"hwmon0/in0_min":      "0\n",
+	})
+
+	got, err := parseSysClassHwmon(filepath.Join(root, "hwmon0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"hwmon": "hwmon0",
+		"name":  "coretemp",
+		"temp": []interface{}{
+			map[string]interface{}{"sensor": "temp1", "label": "Package id 0", "input": 45.0, "max": 100.0, "crit": 105.0},
+			map[string]interface{}{"sensor": "temp2", "label": nil, "input": 40.5},
+			map[string]interface{}{"sensor": "temp10", "label": nil, "input": 30.0},
+		},
+		"fan": []interface{}{
+			map[string]interface{}{"sensor": "fan1", "label": nil, "input": int64(1200)},
+		},
+		"in": []interface{}{
+			map[string]interface{}{"sensor": "in0", "label": nil, "input": 1.104, "min": 0.0},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestParseSysClassThermalZone(t *testing.T) {
+	root := writeSysfsFixture(t, map[string]string{
+		"thermal_zone0/type":              "x86_pkg_temp\n",
+		"thermal_zone0/policy":            "step_wise\n",
+		"thermal_zone0/temp":              "42000\n",
+		"thermal_zone0/trip_point_1_type": "critical\n",
+		"thermal_zone0/trip_point_1_temp": "105000\n",
+		"thermal_zone0/trip_point_1_hyst": "2000\n",
+		"thermal_zone0/trip_point_0_type": "passive\n",
+		"thermal_zone0/trip_point_0_temp": "95000\n",
+	})
+
+	got, err := parseSysClassThermalZone(filepath.Join(root, "thermal_zone0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"zone":   "thermal_zone0",
+		"type":   "x86_pkg_temp",
+		"policy": "step_wise",
+		"mode":   nil,
+		"temp":   42.0,
+		"trip_points": []interface{}{
+			map[string]interface{}{"trip": int64(0), "type": "passive", "temp": 95.0, "hyst": nil},
+			map[string]interface{}{"trip": int64(1), "type": "critical", "temp": 105.0, "hyst": 2.0},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestParseSysClassPowerSupply(t *testing.T) {
+	root := writeSysfsFixture(t, map[string]string{
+		"BAT0/type":                         "Battery\n",
+		"BAT0/status":                       "Discharging\n",
+		"BAT0/capacity":                     "80\n",
+		"BAT0/charge_now":                   "4000000\n",
+		"BAT0/charge_full_design":           "5000000\n",
+		"BAT0/charge_control_end_threshold": "80\n",
+		"BAT0/voltage_now":                  "12345000\n",
+		"BAT0/current_now":                  "1500000\n",
+		"BAT0/uevent":                       "POWER_SUPPLY_NAME=BAT0\n",
+	})
+
+	got, err := parseSysClassPowerSupply(filepath.Join(root, "BAT0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"name":                         "BAT0",
+		"type":                         "Battery",
+		"status":                       "Discharging",
+		"capacity":                     int64(80),
+		"charge_now":                   4.0,
+		"charge_full_design":           5.0,
+		"charge_control_end_threshold": int64(80),
+		"voltage_now":                  12.345,
+		"current_now":                  1.5,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build linux && !386 && !amd64 && !arm && !arm64 && !loong64 && !mips && !mipsle && !mips64 && !mips64le && !ppc64 && !ppc64le && !riscv64 && !s390x
+
+package parser
+
+// syscallNames maps the system call numbers of the architecture to their
+// names. It is empty for the architectures gen_syscall_names.go does not know,
+// for which /proc/<pid>/syscall has no name.
+var syscallNames = map[int64]string{}
@@ -0,0 +1,361 @@
+// Code generated by gen_syscall_names.go from golang.org/x/sys/unix/zsysnum_linux_mips64le.go. DO NOT EDIT.
+
+package parser
+
+// syscallNames maps the system call numbers of the architecture to their names
+var syscallNames = map[int64]string{
+	5000: "read",
+	5001: "write",
+	5002: "open",
+	5003: "close",
+	5004: "stat",
+	5005: "fstat",
+	5006: "lstat",
+	5007: "poll",
+	5008: "lseek",
+	5009: "mmap",
+	5010: "mprotect",
+	5011: "munmap",
+	5012: "brk",
+	5013: "rt_sigaction",
+	5014: "rt_sigprocmask",
+	5015: "ioctl",
+	5016: "pread64",
+	5017: "pwrite64",
+	5018: "readv",
+	5019: "writev",
+	5020: "access",
+	5021: "pipe",
+	5022: "_newselect",
+	5023: "sched_yield",
+	5024: "mremap",
+	5025: "msync",
+	5026: "mincore",
+	5027: "madvise",
+	5028: "shmget",
+	5029: "shmat",
+	5030: "shmctl",
+	5031: "dup",
+	5032: "dup2",
+	5033: "pause",
+	5034: "nanosleep",
+	5035: "getitimer",
+	5036: "setitimer",
+	5037: "alarm",
+	5038: "getpid",
+	5039: "sendfile",
+	5040: "socket",
+	5041: "connect",
+	5042: "accept",
+	5043: "sendto",
+	5044: "recvfrom",
+	5045: "sendmsg",
+	5046: "recvmsg",
+	5047: "shutdown",
+	5048: "bind",
+	5049: "listen",
+	5050: "getsockname",
+	5051: "getpeername",
+	5052: "socketpair",
+	5053: "setsockopt",
+	5054: "getsockopt",
+	5055: "clone",
+	5056: "fork",
+	5057: "execve",
+	5058: "exit",
+	5059: "wait4",
+	5060: "kill",
+	5061: "uname",
+	5062: "semget",
+	5063: "semop",
+	5064: "semctl",
+	5065: "shmdt",
+	5066: "msgget",
+	5067: "msgsnd",
+	5068: "msgrcv",
+	5069: "msgctl",
+	5070: "fcntl",
+	5071: "flock",
+	5072: "fsync",
+	5073: "fdatasync",
+	5074: "truncate",
+	5075: "ftruncate",
+	5076: "getdents",
+	5077: "getcwd",
+	5078: "chdir",
+	5079: "fchdir",
+	5080: "rename",
+	5081: "mkdir",
+	5082: "rmdir",
+	5083: "creat",
+	5084: "link",
+	5085: "unlink",
+	5086: "symlink",
+	5087: "readlink",
+	5088: "chmod",
+	5089: "fchmod",
+	5090: "chown",
+	5091: "fchown",
+	5092: "lchown",
+	5093: "umask",
+	5094: "gettimeofday",
+	5095: "getrlimit",
+	5096: "getrusage",
+	5097: "sysinfo",
+	5098: "times",
+	5099: "ptrace",
+	5100: "getuid",
+	5101: "syslog",
+	5102: "getgid",
+	5103: "setuid",
+	5104: "setgid",
+	5105: "geteuid",
+	5106: "getegid",
+	5107: "setpgid",
+	5108: "getppid",
+	5109: "getpgrp",
+	5110: "setsid",
+	5111: "setreuid",
+	5112: "setregid",
+	5113: "getgroups",
+	5114: "setgroups",
+	5115: "setresuid",
+	5116: "getresuid",
+	5117: "setresgid",
+	5118: "getresgid",
+	5119: "getpgid",
+	5120: "setfsuid",
+	5121: "setfsgid",
+	5122: "getsid",
+	5123: "capget",
+	5124: "capset",
+	5125: "rt_sigpending",
+	5126: "rt_sigtimedwait",
+	5127: "rt_sigqueueinfo",
+	5128: "rt_sigsuspend",
+	5129: "sigaltstack",
+	5130: "utime",
+	5131: "mknod",
+	5132: "personality",
+	5133: "ustat",
+	5134: "statfs",
+	5135: "fstatfs",
+	5136: "sysfs",
+	5137: "getpriority",
+	5138: "setpriority",
+	5139: "sched_setparam",
+	5140: "sched_getparam",
+	5141: "sched_setscheduler",
+	5142: "sched_getscheduler",
+	5143: "sched_get_priority_max",
+	5144: "sched_get_priority_min",
+	5145: "sched_rr_get_interval",
+	5146: "mlock",
+	5147: "munlock",
+	5148: "mlockall",
+	5149: "munlockall",
+	5150: "vhangup",
+	5151: "pivot_root",
+	5152: "_sysctl",
+	5153: "prctl",
+	5154: "adjtimex",
+	5155: "setrlimit",
+	5156: "chroot",
+	5157: "sync",
+	5158: "acct",
+	5159: "settimeofday",
+	5160: "mount",
+	5161: "umount2",
+	5162: "swapon",
+	5163: "swapoff",
+	5164: "reboot",
+	5165: "sethostname",
+	5166: "setdomainname",
+	5167: "create_module",
+	5168: "init_module",
+	5169: "delete_module",
+	5170: "get_kernel_syms",
+	5171: "query_module",
+	5172: "quotactl",
+	5173: "nfsservctl",
+	5174: "getpmsg",
+	5175: "putpmsg",
+	5176: "afs_syscall",
+	5177: "reserved177",
+	5178: "gettid",
+	5179: "readahead",
+	5180: "setxattr",
+	5181: "lsetxattr",
+	5182: "fsetxattr",
+	5183: "getxattr",
+	5184: "lgetxattr",
+	5185: "fgetxattr",
+	5186: "listxattr",
+	5187: "llistxattr",
+	5188: "flistxattr",
+	5189: "removexattr",
+	5190: "lremovexattr",
+	5191: "fremovexattr",
+	5192: "tkill",
+	5193: "reserved193",
+	5194: "futex",
+	5195: "sched_setaffinity",
+	5196: "sched_getaffinity",
+	5197: "cacheflush",
+	5198: "cachectl",
+	5199: "sysmips",
+	5200: "io_setup",
+	5201: "io_destroy",
+	5202: "io_getevents",
+	5203: "io_submit",
+	5204: "io_cancel",
+	5205: "exit_group",
+	5206: "lookup_dcookie",
+	5207: "epoll_create",
+	5208: "epoll_ctl",
+	5209: "epoll_wait",
+	5210: "remap_file_pages",
+	5211: "rt_sigreturn",
+	5212: "set_tid_address",
+	5213: "restart_syscall",
+	5214: "semtimedop",
+	5215: "fadvise64",
+	5216: "timer_create",
+	5217: "timer_settime",
+	5218: "timer_gettime",
+	5219: "timer_getoverrun",
+	5220: "timer_delete",
+	5221: "clock_settime",
+	5222: "clock_gettime",
+	5223: "clock_getres",
+	5224: "clock_nanosleep",
+	5225: "tgkill",
+	5226: "utimes",
+	5227: "mbind",
+	5228: "get_mempolicy",
+	5229: "set_mempolicy",
+	5230: "mq_open",
+	5231: "mq_unlink",
+	5232: "mq_timedsend",
+	5233: "mq_timedreceive",
+	5234: "mq_notify",
+	5235: "mq_getsetattr",
+	5236: "vserver",
+	5237: "waitid",
+	5239: "add_key",
+	5240: "request_key",
+	5241: "keyctl",
+	5242: "set_thread_area",
+	5243: "inotify_init",
+	5244: "inotify_add_watch",
+	5245: "inotify_rm_watch",
+	5246: "migrate_pages",
+	5247: "openat",
+	5248: "mkdirat",
+	5249: "mknodat",
+	5250: "fchownat",
+	5251: "futimesat",
+	5252: "newfstatat",
+	5253: "unlinkat",
+	5254: "renameat",
+	5255: "linkat",
+	5256: "symlinkat",
+	5257: "readlinkat",
+	5258: "fchmodat",
+	5259: "faccessat",
+	5260: "pselect6",
+	5261: "ppoll",
+	5262: "unshare",
+	5263: "splice",
+	5264: "sync_file_range",
+	5265: "tee",
+	5266: "vmsplice",
+	5267: "move_pages",
+	5268: "set_robust_list",
+	5269: "get_robust_list",
+	5270: "kexec_load",
+	5271: "getcpu",
+	5272: "epoll_pwait",
+	5273: "ioprio_set",
+	5274: "ioprio_get",
+	5275: "utimensat",
+	5276: "signalfd",
+	5277: "timerfd",
+	5278: "eventfd",
+	5279: "fallocate",
+	5280: "timerfd_create",
+	5281: "timerfd_gettime",
+	5282: "timerfd_settime",
+	5283: "signalfd4",
+	5284: "eventfd2",
+	5285: "epoll_create1",
+	5286: "dup3",
+	5287: "pipe2",
+	5288: "inotify_init1",
+	5289: "preadv",
+	5290: "pwritev",
+	5291: "rt_tgsigqueueinfo",
+	5292: "perf_event_open",
+	5293: "accept4",
+	5294: "recvmmsg",
+	5295: "fanotify_init",
+	5296: "fanotify_mark",
+	5297: "prlimit64",
+	5298: "name_to_handle_at",
+	5299: "open_by_handle_at",
+	5300: "clock_adjtime",
+	5301: "syncfs",
+	5302: "sendmmsg",
+	5303: "setns",
+	5304: "process_vm_readv",
+	5305: "process_vm_writev",
+	5306: "kcmp",
+	5307: "finit_module",
+	5308: "getdents64",
+	5309: "sched_setattr",
+	5310: "sched_getattr",
+	5311: "renameat2",
+	5312: "seccomp",
+	5313: "getrandom",
+	5314: "memfd_create",
+	5315: "bpf",
+	5316: "execveat",
+	5317: "userfaultfd",
+	5318: "membarrier",
+	5319: "mlock2",
+	5320: "copy_file_range",
+	5321: "preadv2",
+	5322: "pwritev2",
+	5323: "pkey_mprotect",
+	5324: "pkey_alloc",
+	5325: "pkey_free",
+	5326: "statx",
+	5327: "rseq",
+	5328: "io_pgetevents",
+	5424: "pidfd_send_signal",
+	5425: "io_uring_setup",
+	5426: "io_uring_enter",
+	5427: "io_uring_register",
+	5428: "open_tree",
+	5429: "move_mount",
+	5430: "fsopen",
+	5431: "fsconfig",
+	5432: "fsmount",
+	5433: "fspick",
+	5434: "pidfd_open",
+	5435: "clone3",
+	5436: "close_range",
+	5437: "openat2",
+	5438: "pidfd_getfd",
+	5439: "faccessat2",
+	5440: "process_madvise",
+	5441: "epoll_pwait2",
+	5442: "mount_setattr",
+	5443: "quotactl_fd",
+	5444: "landlock_create_ruleset",
+	5445: "landlock_add_rule",
+	5446: "landlock_restrict_self",
+	5448: "process_mrelease",
+	5449: "futex_waitv",
+	5450: "set_mempolicy_home_node",
+}
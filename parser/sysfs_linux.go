@@ -0,0 +1,151 @@
+//go:build linux
+
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// readSysfsAttr reads a sysfs attribute and returns its content without the
+// trailing newline. Attributes which are not available in the current state
+// of the device fail with e.g. EINVAL (such as speed of a link which is down),
+// and nil is returned for them instead of an error.
+func readSysfsAttr(path string) (interface{}, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		for _, errno := range []syscall.Errno{syscall.EINVAL, syscall.EOPNOTSUPP, syscall.ENODATA, syscall.ENODEV, syscall.ENXIO, syscall.EIO} {
+			if errors.Is(err, errno) {
+				return nil, nil
+			}
+		}
+		return nil, err
+	}
+
+	return strings.TrimRight(string(b), "\n"), nil
+}
+
+// readSysfsTypedAttr reads a sysfs attribute and types it by parseIntegerOrString.
+func readSysfsTypedAttr(path string) (interface{}, error) {
+	val, err := readSysfsAttr(path)
+	if err != nil || val == nil {
+		return val, err
+	}
+	return parseIntegerOrString(val.(string)), nil
+}
+
+// readSysfsIntegerAttr reads a sysfs attribute which holds an integer.
+func readSysfsIntegerAttr(path string) (interface{}, error) {
+	val, err := readSysfsAttr(path)
+	if err != nil || val == nil {
+		return val, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(val.(string)), 10, 64)
+}
+
+// readSysfsBoolAttr reads a sysfs attribute which holds 0 or 1.
+func readSysfsBoolAttr(path string) (interface{}, error) {
+	val, err := readSysfsIntegerAttr(path)
+	if err != nil || val == nil {
+		return val, err
+	}
+	return val.(int64) != 0, nil
+}
+
+// readSysfsAttrs reads all readable attributes (i.e. regular files) in a
+// sysfs directory into an object typed by parseIntegerOrString. The attributes
+// which cannot be read (e.g. write-only ones) are skipped.
+func readSysfsAttrs(dir string, exclude ...string) (map[string]interface{}, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || containsString(exclude, entry.Name()) {
+			continue
+		}
+
+		val, err := readSysfsTypedAttr(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			if errors.Is(err, os.ErrPermission) || errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		result[entry.Name()] = val
+	}
+
+	return result, nil
+}
+
+// readSysfsLinkName returns the base name of a symbolic link target such as
+// device/driver, or nil if there is no such link.
+func readSysfsLinkName(path string) interface{} {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return nil
+	}
+	return filepath.Base(target)
+}
+
+var reSysfsChoice = regexp.MustCompile(`\[([^\]]+)\]`)
+
+// parseSysfsChoice parses an attribute which shows the available choices with
+// the active one in brackets, such as "mq-deadline kyber [bfq] none".
+func parseSysfsChoice(s string) map[string]interface{} {
+	result := map[string]interface{}{
+		"active": nil,
+	}
+	if submatch := reSysfsChoice.FindStringSubmatch(s); len(submatch) == 2 {
+		result["active"] = submatch[1]
+	}
+
+	available := []interface{}{}
+	for _, field := range strings.Fields(s) {
+		available = append(available, strings.Trim(field, "[]"))
+	}
+	result["available"] = available
+
+	return result
+}
+
+// readSysfsChoiceAttr reads a sysfs attribute parsed by parseSysfsChoice.
+func readSysfsChoiceAttr(path string) (interface{}, error) {
+	val, err := readSysfsAttr(path)
+	if err != nil || val == nil {
+		return val, err
+	}
+	return parseSysfsChoice(val.(string)), nil
+}
+
+// listSysfsDir returns the sorted names of the entries in dir which match
+// pattern (e.g. "cpu[0-9]*").
+func listSysfsDir(dir, pattern string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, pattern))
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, p := range paths {
+		names = append(names, filepath.Base(p))
+	}
+	return names, nil
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+//go:build linux
+
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeSysfsFixture creates a fake sysfs tree in a temporary directory and
+// returns its root. files maps paths relative to the root to their contents.
+// Contents beginning with "->" create symbolic links to the rest instead.
+func writeSysfsFixture(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(content) > 2 && content[:2] == "->" {
+			if err := os.Symlink(content[2:], p); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}